@@ -1,3 +0,0 @@
-package etcd
-
-const version = "v2"
@@ -1,7 +1,7 @@
 package ws
 
 import (
-	"github.com/gorilla/websocket"
+	"github.com/zhgwenming/gbalancer/Godeps/_workspace/src/github.com/gorilla/websocket"
 	"io"
 	"log"
 	"time"
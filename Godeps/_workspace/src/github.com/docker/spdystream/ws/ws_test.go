@@ -3,7 +3,7 @@ package ws
 import (
 	"bytes"
 	"github.com/zhgwenming/gbalancer/Godeps/_workspace/src/github.com/docker/spdystream"
-	"github.com/gorilla/websocket"
+	"github.com/zhgwenming/gbalancer/Godeps/_workspace/src/github.com/gorilla/websocket"
 	"io"
 	"log"
 	"net/http"
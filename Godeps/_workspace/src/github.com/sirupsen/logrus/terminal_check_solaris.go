@@ -0,0 +1,11 @@
+package logrus
+
+import (
+	"github.com/zhgwenming/gbalancer/Godeps/_workspace/src/golang.org/x/sys/unix"
+)
+
+// IsTerminal returns true if the given file descriptor is a terminal.
+func isTerminal(fd int) bool {
+	_, err := unix.IoctlGetTermio(fd, unix.TCGETA)
+	return err == nil
+}
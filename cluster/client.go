@@ -5,89 +5,182 @@
 package cluster
 
 import (
+	"encoding/json"
 	"fmt"
-	"github.com/zhgwenming/gbalancer/Godeps/_workspace/src/github.com/coreos/go-etcd/etcd"
 	"github.com/zhgwenming/gbalancer/utils"
-	"log"
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 )
 
-// etcd directory hierarchy:
-// v2/keys
+// key hierarchy in the coordinator:
 //     ├── serviceName
-//     │        ├── cluster1
-//     │        │     ├── leader ── id {ip}
-//     │        │     ├── resource
-//     │        │     │    ├── createdIndex
-//     │        │     │    ├── ...
-//     │        │     │    └── createdIndexN
-//     │        │     ├── node
-//     │        │     │    ├── (node1) ip1 {pid}
-//     │        │     │    ├── (node2) ip2
-//     │        │     │    ├── ...
-//     │        │     │    └── (nodeN) ipN
-//     │        │     └── config
-//     │        │
-//     │        ├── clusterN
+//     │        ├── cluster1
+//     │        │     ├── leader      (election, the layout is up to the coordinator)
+//     │        │     ├── resource
+//     │        │     │    ├── addr1 {addr1}
+//     │        │     │    ├── ...
+//     │        │     │    └── addrN {addrN}
+//     │        │     ├── node
+//     │        │     │    ├── (node1) ip1 {pid}
+//     │        │     │    ├── (node2) ip2
+//     │        │     │    ├── ...
+//     │        │     │    └── (nodeN) ipN
+//     │        │     ├── health
+//     │        │     │    ├── (node1) ip1 {backends view}
+//     │        │     │    ├── ...
+//     │        │     │    └── (nodeN) ipN
+//     │        │     └── config
+//     │        │
+//     │        ├── clusterN
 //     │
 //     ├── serviceNameN
+//
+// the node, health and leader keys are ephemeral, they will be removed
+// by the coordinator once our session expired
+
+const (
+	DefaultTTL = 60
+)
 
 type Client struct {
 	ServiceName string
 	ClusterName string
-	etcdClient  *etcd.Client
 	IPAddress   string
 	Pid         string
+	coord       Coordinator
 }
 
-func NewClient(service, cluster string, server []string) *Client {
-
-	client := etcd.NewClient(server)
+// NewClient creates a client on the coordinator picked by the endpoints,
+// ttl is the session timeout in seconds
+func NewClient(service, cluster string, endpoints []string, ttl int64) (*Client, error) {
+	coord, err := NewCoordinator(endpoints, ttl)
+	if err != nil {
+		return nil, err
+	}
 
 	ip := utils.GetFirstIPAddr()
 	pid := strconv.Itoa(os.Getpid())
-	return &Client{service, cluster, client, ip, pid}
+	return &Client{service, cluster, ip, pid, coord}, nil
+}
+
+// the endpoints can be specified as {"endpoints": [...]}, or in the go-etcd
+// form of {"cluster": {"machines": [...]}}
+type clientConfig struct {
+	Endpoints []string
+	Cluster   struct {
+		Machines []string
+	}
 }
 
-func NewClientFromFile(service, cluster, config string) *Client {
-	client, err := etcd.NewClientFromFile("config.json")
+func NewClientFromFile(service, cluster, config string) (*Client, error) {
+	file, err := os.Open(config)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	defer file.Close()
 
-	ip := utils.GetFirstIPAddr()
-	pid := strconv.Itoa(os.Getpid())
-	return &Client{service, cluster, client, ip, pid}
+	cfg := &clientConfig{}
+	if err := json.NewDecoder(file).Decode(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %s", config, err)
+	}
+
+	endpoints := append(cfg.Endpoints, cfg.Cluster.Machines...)
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%s: no coordinator endpoints", config)
+	}
+
+	return NewClient(service, cluster, endpoints, DefaultTTL)
 }
 
-func (l Client) Prefix() string {
+func (l *Client) Prefix() string {
 	return path.Join(l.ServiceName, l.ClusterName)
 }
 
-func (l Client) LeaderPath() string {
-	return path.Join(l.Prefix(), "leader", "id")
+func (l *Client) LeaderPath() string {
+	return path.Join(l.Prefix(), "leader")
 }
 
-func (l Client) NodePath() string {
+func (l *Client) NodePath() string {
 	return path.Join(l.Prefix(), "node", l.IPAddress)
 }
 
+func (l *Client) ConfigPath() string {
+	return path.Join(l.Prefix(), "config")
+}
+
+func (l *Client) ResourcePath() string {
+	return path.Join(l.Prefix(), "resource")
+}
+
+// Events reports the session and leadership loss
+func (l *Client) Events() <-chan Event {
+	return l.coord.Events()
+}
+
+// Close ends the session, all our ephemeral keys will be removed
+func (l *Client) Close() error {
+	return l.coord.Close()
+}
+
+// Config returns the shared configuration of the cluster
+func (l *Client) Config() (string, error) {
+	return l.coord.Get(l.ConfigPath())
+}
+
+// Resources returns all the resources of the cluster in created order
+func (l *Client) Resources() ([]string, error) {
+	return l.coord.List(l.ResourcePath() + "/")
+}
+
+// AddResource adds a new resource as resource/value
+func (l *Client) AddResource(value string) error {
+	return l.coord.Put(path.Join(l.ResourcePath(), value), value, false)
+}
+
+// WatchConfig blocks and notifies the changed channel on every change of the
+// shared configuration or the resources, returns on error or stop
+func (l *Client) WatchConfig(changed chan<- struct{}, stop chan bool) error {
+	keys := make(chan string)
+	errChan := make(chan error, 1)
+	done := make(chan struct{})
+
+	configPath := l.ConfigPath()
+	resourcePath := l.ResourcePath() + "/"
+
+	go func() {
+		errChan <- l.coord.Watch(l.Prefix()+"/", keys, done)
+	}()
+	defer close(done)
+
+	for {
+		select {
+		case key := <-keys:
+			// health views got updated periodically, skip them
+			if key == configPath || strings.HasPrefix(key, resourcePath) {
+				changed <- struct{}{}
+			}
+		case err := <-errChan:
+			return err
+		case <-stop:
+			return nil
+		}
+	}
+}
+
 func (l *Client) FindInstance() (int, error) {
 	var pid int
-	client := l.etcdClient
 	nodePath := l.NodePath()
 
-	resp, err := client.Get(nodePath, false, false)
+	value, err := l.coord.Get(nodePath)
 	if err != nil {
-		//log.Printf("No node defined in etcd")
+		//log.Printf("No node defined")
 		return pid, err
 	} else {
 		// found a exist node
-		value := resp.Node.Value
 		pid, err = strconv.Atoi(value)
 		if err != nil {
 			log.Printf("Got a wrong format of pid %v", value)
@@ -101,46 +194,9 @@ func (l *Client) FindInstance() (int, error) {
 	}
 }
 
-func (l *Client) Lock(key, value string, ttl uint64) error {
-	client := l.etcdClient
-
-	// curl -X PUT http://127.0.0.1:4001/mod/v2/leader/{clustername}?ttl=60 -d name=servername
-	// not supported by etcd client yet.
-	// Issue will exist if we use Update here
-	// since multiple instances share the same netns but not processns will make multiple instances runable
-	// so we always use Create()
-	if resp, err := client.Create(key, value, ttl); err != nil {
-		// to avoid the following race conditions:
-		// 1. multiple instances might be waiting on this node
-		// 2. a instance on same node shares same netns but different processns, will
-		// 	cause this looping forever
-		log.Printf("Error to create node: %s", err)
-		return err
-	} else {
-		//log.Printf("No instance exist on this node, starting")
-		go func() {
-			sleeptime := time.Duration(ttl / 3)
-			//log.Printf("Sleep time is %d", sleeptime)
-			for {
-				index := resp.EtcdIndex
-				time.Sleep(sleeptime * time.Second)
-				// update the ttl periodically
-				// error might happens for the following cases:
-				// 1. node got modified unexpectedly
-				// 2. we got stopped, process stucked
-				resp, err = client.CompareAndSwap(key, value, ttl, value, index)
-				if err != nil {
-					log.Fatal("Unexpected lost our node lock", err)
-				}
-			}
-		}()
-		return nil
-	}
-}
-
 // Node Register, dead instance on same node should be
 // replaced ASAP to avoid service redistribution
-func (l *Client) Register(ttl uint64) error {
+func (l *Client) Register() error {
 	pid := l.Pid
 	nodePath := l.NodePath()
 
@@ -148,35 +204,40 @@ func (l *Client) Register(ttl uint64) error {
 		existPid, err := l.FindInstance()
 		//log.Printf("error is %s", err)
 		// found a running pid
-		if err == nil {
+		if err == nil && strconv.Itoa(existPid) != pid {
 			err = fmt.Errorf("A exist instance on this node running with %d", existPid)
 			return err
 		}
 
-		if err := l.Lock(nodePath, pid, ttl); err == nil {
+		// multiple instances share the same netns but not processns might
+		// be racing here, so we always create the node
+		owned, err := l.coord.Create(nodePath, pid)
+		if err == nil && owned {
 			log.Printf("Client registered")
 			return nil
 		}
 
-		// retry after 1s
+		if err != nil {
+			log.Printf("Error to create node: %s", err)
+		}
+
+		// retry after 1s, the node of a dead instance expires with its session
 		time.Sleep(time.Second)
 	}
 }
 
-// leader election can take some time to wait ttl expires
-func (l *Client) BecomeLeader(ttl uint64) {
-	id := l.IPAddress
-
-	leaderPath := l.LeaderPath()
-	log.Printf("leader path: %s", leaderPath)
+// leader election can take some time to wait ttl expires, it blocks until
+// we got the leadership, the loss of it will be reported as LeadershipLost
+func (l *Client) BecomeLeader() error {
+	if err := l.coord.Campaign(l.LeaderPath(), l.IPAddress); err != nil {
+		return err
+	}
 
-	for {
-		if err := l.Lock(leaderPath, id, ttl); err == nil {
-			log.Printf("No leader exist, taking the leadership")
-			return
-		}
+	log.Printf("No leader exist, taking the leadership")
+	return nil
+}
 
-		// retry after 5 secs
-		time.Sleep(5 * time.Second)
-	}
+// Resign gives up the leadership so a standby can take over immediately
+func (l *Client) Resign() error {
+	return l.coord.Resign()
 }
@@ -0,0 +1,175 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package cluster
+
+import (
+	"fmt"
+	logger "github.com/zhgwenming/gbalancer/log"
+	"strings"
+	"sync"
+)
+
+var (
+	log = logger.New("cluster")
+)
+
+type EventType int
+
+const (
+	// the session expired, all our ephemeral keys are gone
+	LeaseLost EventType = iota
+	// the leader key got removed
+	LeadershipLost
+)
+
+func (t EventType) String() string {
+	switch t {
+	case LeaseLost:
+		return "lease lost"
+	case LeadershipLost:
+		return "leadership lost"
+	}
+	return "unknown event"
+}
+
+type Event struct {
+	Type EventType
+	Err  error
+}
+
+// Coordinator is the coordination service the cluster runs on, it provides
+// a key space, a session the ephemeral keys are attached to and the leader
+// election, the keys are slash separated paths
+type Coordinator interface {
+	// Get returns the value of the key, ErrKeyNotFound if it doesn't exist
+	Get(key string) (string, error)
+
+	// List returns the values of the keys under prefix in created order
+	List(prefix string) ([]string, error)
+
+	// Put sets the value of the key, ephemeral keys are removed along with our session
+	Put(key, value string, ephemeral bool) error
+
+	// Create sets the ephemeral key only if it doesn't exist, returns true
+	// if the key is owned by our session afterwards
+	Create(key, value string) (bool, error)
+
+	// Campaign blocks until we become the leader of the election named key
+	Campaign(key, value string) error
+
+	// Resign gives up the leadership if we have it
+	Resign() error
+
+	// Watch notifies the keys changed under prefix, returns on error or stop
+	Watch(prefix string, changed chan<- string, stop <-chan struct{}) error
+
+	// Events reports the session and leadership loss
+	Events() <-chan Event
+
+	// Close ends the session, all our ephemeral keys will be removed
+	Close() error
+}
+
+// NewCoordinator picks the coordinator by the scheme of the endpoints:
+//
+//	consul://host:8500    - consul sessions and kv
+//	file:///var/lib/dir   - file locks in a local directory, single host only
+//	etcd://host:2379      - etcd v3, also the default without a scheme or with http(s)://
+//
+// ttl is the session timeout in seconds
+func NewCoordinator(endpoints []string, ttl int64) (Coordinator, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no coordinator endpoints specified")
+	}
+
+	scheme := endpointScheme(endpoints[0])
+	hosts := make([]string, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if endpointScheme(ep) != scheme {
+			return nil, fmt.Errorf("mixed coordinator endpoints %v", endpoints)
+		}
+		hosts = append(hosts, strings.TrimPrefix(ep, scheme+"://"))
+	}
+
+	switch scheme {
+	case "consul":
+		return newConsulCoordinator(hosts, ttl), nil
+	case "file":
+		if len(hosts) != 1 {
+			return nil, fmt.Errorf("only one directory is allowed with file coordinator")
+		}
+		return newFileCoordinator(hosts[0])
+	case "etcd":
+		return newEtcdCoordinator(hosts, ttl)
+	case "", "http", "https":
+		return newEtcdCoordinator(endpoints, ttl)
+	}
+	return nil, fmt.Errorf("unknown coordinator %s", scheme)
+}
+
+func endpointScheme(ep string) string {
+	parts := strings.SplitN(ep, "://", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[0]
+}
+
+// eventLatch holds the events until they're read, the session keeper is
+// never blocked and no event gets dropped. The pending events of the same
+// type are merged into one while nobody reads.
+type eventLatch struct {
+	mutex   sync.Mutex
+	pending []Event
+	kick    chan struct{}
+	events  chan Event
+}
+
+func newEventLatch() *eventLatch {
+	l := &eventLatch{kick: make(chan struct{}, 1), events: make(chan Event)}
+	go l.deliver()
+	return l
+}
+
+// emit reports the event without blocking the session keeper
+func (l *eventLatch) emit(t EventType, err error) {
+	log.Printf("%s: %v", t, err)
+
+	l.mutex.Lock()
+	merged := false
+	for i := range l.pending {
+		if l.pending[i].Type == t {
+			l.pending[i].Err = err
+			merged = true
+		}
+	}
+	if !merged {
+		l.pending = append(l.pending, Event{t, err})
+	}
+	l.mutex.Unlock()
+
+	select {
+	case l.kick <- struct{}{}:
+	default:
+	}
+}
+
+// deliver hands the pending events over to the reader in order
+func (l *eventLatch) deliver() {
+	for range l.kick {
+		for {
+			l.mutex.Lock()
+			if len(l.pending) == 0 {
+				l.mutex.Unlock()
+				break
+			}
+			ev := l.pending[0]
+			l.pending = l.pending[1:]
+			l.mutex.Unlock()
+
+			l.events <- ev
+		}
+	}
+}
@@ -0,0 +1,510 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ConsulRequestTimeout = 5 * time.Second
+	// how long a blocking query waits for a change
+	ConsulWaitTime = 30 * time.Second
+	// the minimum session ttl accepted by consul
+	consulMinTTL = 10
+)
+
+// consulCoordinator talks to the consul http api, the session is a consul
+// session with the delete behavior, the ephemeral keys are acquired by it
+// so they're removed once the session got invalidated. The leader is the
+// session holding the lock of the election key.
+type consulCoordinator struct {
+	endpoints []string
+	client    *http.Client
+	stream    *http.Client // for blocking queries
+	ttl       int64
+	events    *eventLatch
+	mutex     sync.Mutex
+	session   string
+	leaderKey string
+	resigned  chan struct{}
+}
+
+type consulKV struct {
+	Key         string
+	Value       []byte
+	CreateIndex uint64
+	ModifyIndex uint64
+	Session     string
+}
+
+func newConsulCoordinator(endpoints []string, ttl int64) *consulCoordinator {
+	eps := make([]string, 0, len(endpoints))
+	for _, ep := range endpoints {
+		eps = append(eps, "http://"+strings.TrimRight(ep, "/"))
+	}
+
+	if ttl < consulMinTTL {
+		ttl = consulMinTTL
+	}
+
+	client := &http.Client{Timeout: ConsulRequestTimeout}
+	stream := &http.Client{Timeout: ConsulWaitTime + ConsulRequestTimeout}
+	return &consulCoordinator{endpoints: eps, client: client, stream: stream,
+		ttl: ttl, events: newEventLatch()}
+}
+
+// do tries the endpoints in order until one of them responds, the body of
+// the response is returned along with the X-Consul-Index header
+func (c *consulCoordinator) do(client *http.Client, method, api string, query url.Values, body string) ([]byte, uint64, error) {
+	err := fmt.Errorf("no consul endpoint specified")
+	for _, ep := range c.endpoints {
+		u := ep + "/v1/" + api
+		if len(query) > 0 {
+			u += "?" + query.Encode()
+		}
+
+		var req *http.Request
+		req, err = http.NewRequest(method, u, strings.NewReader(body))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var resp *http.Response
+		resp, err = client.Do(req)
+		if err != nil {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return data, index, nil
+		case http.StatusNotFound:
+			return nil, index, ErrKeyNotFound
+		default:
+			msg := string(bytes.TrimSpace(data))
+			return nil, 0, fmt.Errorf("consul %s %s: %s (%d)", method, api, msg, resp.StatusCode)
+		}
+	}
+	return nil, 0, err
+}
+
+// getKVs queries the key or the prefix if recurse is set, it blocks until
+// the data got changed after index if index isn't 0
+func (c *consulCoordinator) getKVs(key string, recurse bool, index uint64) ([]*consulKV, uint64, error) {
+	query := url.Values{}
+	client := c.client
+	if recurse {
+		query.Set("recurse", "")
+	}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", ConsulWaitTime.String())
+		client = c.stream
+	}
+
+	kvs := make([]*consulKV, 0)
+	data, index, err := c.do(client, "GET", "kv/"+key, query, "")
+	if err == ErrKeyNotFound {
+		return kvs, index, nil
+	} else if err != nil {
+		return kvs, index, err
+	}
+
+	err = json.Unmarshal(data, &kvs)
+	return kvs, index, err
+}
+
+// putKV sets the key, the key is acquired by the session if it's specified
+func (c *consulCoordinator) putKV(key, value, session string) (bool, error) {
+	query := url.Values{}
+	if session != "" {
+		query.Set("acquire", session)
+	}
+
+	data, _, err := c.do(c.client, "PUT", "kv/"+key, query, value)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(data)) == "true", nil
+}
+
+func (c *consulCoordinator) Events() <-chan Event {
+	return c.events.events
+}
+
+// getSession returns the session of all our keys, a new one is
+// created and renewed if we don't have one yet
+func (c *consulCoordinator) getSession() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.session != "" {
+		return c.session, nil
+	}
+
+	request := map[string]string{
+		"Name":      "gbalancer",
+		"TTL":       fmt.Sprintf("%ds", c.ttl),
+		"Behavior":  "delete",
+		"LockDelay": "0s",
+	}
+	body, _ := json.Marshal(request)
+
+	data, _, err := c.do(c.client, "PUT", "session/create", nil, string(body))
+	if err != nil {
+		return "", err
+	}
+
+	resp := &struct{ ID string }{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return "", err
+	}
+
+	c.session = resp.ID
+	go c.renew(resp.ID)
+	return resp.ID, nil
+}
+
+// renew refreshes the session every ttl/3 seconds, transient errors are
+// retried until the session would have expired
+func (c *consulCoordinator) renew(id string) {
+	interval := time.Duration(c.ttl) * time.Second / 3
+	deadline := time.Now().Add(time.Duration(c.ttl) * time.Second)
+
+	var err error
+	for {
+		time.Sleep(interval)
+
+		c.mutex.Lock()
+		current := c.session
+		c.mutex.Unlock()
+
+		// destroyed by ourself
+		if current != id {
+			return
+		}
+
+		_, _, err = c.do(c.client, "PUT", "session/renew/"+id, nil, "")
+		if err == nil {
+			deadline = time.Now().Add(time.Duration(c.ttl) * time.Second)
+			interval = time.Duration(c.ttl) * time.Second / 3
+			continue
+		}
+
+		if err == ErrKeyNotFound {
+			err = fmt.Errorf("session %s expired", id)
+			break
+		}
+
+		if time.Now().After(deadline) {
+			break
+		}
+
+		log.Printf("Failed to renew session %s: %s", id, err)
+		interval = time.Second
+	}
+
+	c.mutex.Lock()
+	leader := false
+	if c.session == id {
+		leader = c.dropLeadership() != ""
+		c.session = ""
+	}
+	c.mutex.Unlock()
+
+	if leader {
+		c.events.emit(LeadershipLost, err)
+	}
+	c.events.emit(LeaseLost, err)
+}
+
+// Close destroys the session, all our keys will be removed
+func (c *consulCoordinator) Close() error {
+	c.mutex.Lock()
+	id := c.session
+	c.session = ""
+	c.dropLeadership()
+	c.mutex.Unlock()
+
+	if id == "" {
+		return nil
+	}
+	_, _, err := c.do(c.client, "PUT", "session/destroy/"+id, nil, "")
+	return err
+}
+
+func (c *consulCoordinator) Get(key string) (string, error) {
+	kvs, _, err := c.getKVs(key, false, 0)
+	if err != nil {
+		return "", err
+	}
+
+	if len(kvs) == 0 {
+		return "", ErrKeyNotFound
+	}
+	return string(kvs[0].Value), nil
+}
+
+func (c *consulCoordinator) List(prefix string) ([]string, error) {
+	values := make([]string, 0)
+
+	kvs, _, err := c.getKVs(prefix, true, 0)
+	if err != nil {
+		return values, err
+	}
+
+	sort.Sort(byCreateIndex(kvs))
+	for _, kv := range kvs {
+		// the folder itself
+		if kv.Key == prefix {
+			continue
+		}
+		values = append(values, string(kv.Value))
+	}
+	return values, nil
+}
+
+func (c *consulCoordinator) Put(key, value string, ephemeral bool) error {
+	if !ephemeral {
+		_, err := c.putKV(key, value, "")
+		return err
+	}
+
+	owned, err := c.Create(key, value)
+	if err == nil && !owned {
+		err = fmt.Errorf("%s is held by another session", key)
+	}
+	return err
+}
+
+// Create acquires the key, which succeeds if it's free or already held by us
+func (c *consulCoordinator) Create(key, value string) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+	return c.putKV(key, value, session)
+}
+
+// Campaign tries to acquire the election key, and waits for it
+// to be released by the current holder
+func (c *consulCoordinator) Campaign(election, value string) error {
+	for {
+		session, err := c.getSession()
+		if err != nil {
+			log.Printf("Failed to create session: %s", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		acquired, err := c.putKV(election, value, session)
+		if err != nil {
+			log.Printf("Failed to campaign: %s", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		if acquired {
+			c.mutex.Lock()
+			if c.session != session {
+				// lost the session right after
+				c.mutex.Unlock()
+				continue
+			}
+			c.leaderKey = election
+			c.resigned = make(chan struct{})
+			go c.watchLeadership(election, session, c.resigned)
+			c.mutex.Unlock()
+			return nil
+		}
+
+		if err := c.waitRelease(election); err != nil {
+			log.Printf("Failed to wait for leadership: %s", err)
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// waitRelease blocks until the election key isn't held by any session
+func (c *consulCoordinator) waitRelease(election string) error {
+	var index uint64
+	for {
+		kvs, next, err := c.getKVs(election, false, index)
+		if err != nil {
+			return err
+		}
+
+		if len(kvs) == 0 || kvs[0].Session == "" {
+			return nil
+		}
+
+		// the index might go backwards after a snapshot restore
+		if next < index {
+			next = 0
+		}
+		index = next
+	}
+}
+
+// dropLeadership clears the leadership and stops the watcher, mutex needs to be held
+func (c *consulCoordinator) dropLeadership() string {
+	key := c.leaderKey
+	if key != "" {
+		c.leaderKey = ""
+		close(c.resigned)
+	}
+	return key
+}
+
+// watchLeadership watches the election key until it's not held by our session
+func (c *consulCoordinator) watchLeadership(key, session string, resigned chan struct{}) {
+	var index uint64
+	var err error
+	for {
+		var kvs []*consulKV
+		var next uint64
+		kvs, next, err = c.getKVs(key, false, index)
+
+		select {
+		case <-resigned:
+			return
+		default:
+		}
+
+		if err != nil {
+			log.Printf("Watch on %s broken: %s", key, err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		if len(kvs) == 0 || kvs[0].Session != session {
+			err = fmt.Errorf("lock of %s released", key)
+			break
+		}
+
+		if next < index {
+			next = 0
+		}
+		index = next
+	}
+
+	c.mutex.Lock()
+	leader := c.leaderKey == key
+	if leader {
+		c.dropLeadership()
+	}
+	c.mutex.Unlock()
+
+	if leader {
+		c.events.emit(LeadershipLost, err)
+	}
+}
+
+// Resign releases the election key so a standby can take over immediately
+func (c *consulCoordinator) Resign() error {
+	c.mutex.Lock()
+	key := c.dropLeadership()
+	session := c.session
+	c.mutex.Unlock()
+
+	if key == "" || session == "" {
+		return nil
+	}
+
+	query := url.Values{}
+	query.Set("release", session)
+	_, _, err := c.do(c.client, "PUT", "kv/"+key, query, "")
+	return err
+}
+
+// Watch runs blocking queries on the prefix and compares the modify index
+// of the keys to find out the changed ones
+func (c *consulCoordinator) Watch(prefix string, changed chan<- string, stop <-chan struct{}) error {
+	kvs, index, err := c.getKVs(prefix, true, 0)
+	if err != nil {
+		return err
+	}
+
+	modified := make(map[string]uint64, len(kvs))
+	for _, kv := range kvs {
+		modified[kv.Key] = kv.ModifyIndex
+	}
+
+	for {
+		var next uint64
+		kvs, next, err = c.getKVs(prefix, true, index)
+
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		if err != nil {
+			return err
+		}
+
+		current := make(map[string]uint64, len(kvs))
+		keys := make([]string, 0)
+		for _, kv := range kvs {
+			current[kv.Key] = kv.ModifyIndex
+			if modified[kv.Key] != kv.ModifyIndex {
+				keys = append(keys, kv.Key)
+			}
+		}
+		for key := range modified {
+			if _, ok := current[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		modified = current
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			select {
+			case changed <- key:
+			case <-stop:
+				return nil
+			}
+		}
+
+		if next < index {
+			next = 0
+		}
+		index = next
+	}
+}
+
+type byCreateIndex []*consulKV
+
+func (kvs byCreateIndex) Len() int {
+	return len(kvs)
+}
+
+func (kvs byCreateIndex) Less(i, j int) bool {
+	return kvs[i].CreateIndex < kvs[j].CreateIndex
+}
+
+func (kvs byCreateIndex) Swap(i, j int) {
+	kvs[i], kvs[j] = kvs[j], kvs[i]
+}
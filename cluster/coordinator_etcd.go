@@ -0,0 +1,345 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/zhgwenming/gbalancer/Godeps/_workspace/src/go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "github.com/zhgwenming/gbalancer/Godeps/_workspace/src/go.etcd.io/etcd/client/v3"
+	"github.com/zhgwenming/gbalancer/Godeps/_workspace/src/go.etcd.io/etcd/client/v3/concurrency"
+	"sync"
+	"time"
+)
+
+const (
+	EtcdRequestTimeout = 5 * time.Second
+)
+
+var (
+	ErrKeyNotFound = errors.New("key not found")
+)
+
+// the session is a concurrency.Session, all the ephemeral keys are attached
+// to its lease and removed by etcd once we stopped refreshing it.
+// the leader election is a concurrency.Election on the session
+type etcdCoordinator struct {
+	client   *clientv3.Client
+	ttl      int64
+	events   *eventLatch
+	mutex    sync.Mutex
+	session  *concurrency.Session
+	election *concurrency.Election
+	resigned chan struct{}
+}
+
+func newEtcdCoordinator(endpoints []string, ttl int64) (*etcdCoordinator, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: EtcdRequestTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &etcdCoordinator{client: client, ttl: ttl, events: newEventLatch()}, nil
+}
+
+func (e *etcdCoordinator) Events() <-chan Event {
+	return e.events.events
+}
+
+// getSession returns the session shared by all our keys, a new one is
+// created if we don't have one yet, the lease is kept alive by the session
+func (e *etcdCoordinator) getSession() (*concurrency.Session, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.session != nil {
+		return e.session, nil
+	}
+
+	s, err := concurrency.NewSession(e.client, concurrency.WithTTL(int(e.ttl)))
+	if err != nil {
+		return nil, err
+	}
+
+	e.session = s
+	go e.watchSession(s)
+	return s, nil
+}
+
+// watchSession reports the loss of the session, the keep alive gives up
+// once the lease expired or got revoked
+func (e *etcdCoordinator) watchSession(s *concurrency.Session) {
+	<-s.Done()
+
+	e.mutex.Lock()
+	lost := e.session == s
+	leader := false
+	if lost {
+		leader = e.dropLeadership() != nil
+		e.session = nil
+	}
+	e.mutex.Unlock()
+
+	// closed by ourself
+	if !lost {
+		return
+	}
+
+	err := fmt.Errorf("lease %x expired", s.Lease())
+	if leader {
+		e.events.emit(LeadershipLost, err)
+	}
+	e.events.emit(LeaseLost, err)
+}
+
+// Close revokes the session lease, all our keys will be removed, and
+// closes the connections to etcd
+func (e *etcdCoordinator) Close() error {
+	e.mutex.Lock()
+	s := e.session
+	e.session = nil
+	e.dropLeadership()
+	e.mutex.Unlock()
+
+	var err error
+	if s != nil {
+		err = s.Close()
+	}
+	if cerr := e.client.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (e *etcdCoordinator) Get(key string) (string, error) {
+	ctx, cancel := context.WithTimeout(e.client.Ctx(), EtcdRequestTimeout)
+	defer cancel()
+
+	resp, err := e.client.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Kvs) == 0 {
+		return "", ErrKeyNotFound
+	}
+	return string(resp.Kvs[0].Value), nil
+}
+
+func (e *etcdCoordinator) List(prefix string) ([]string, error) {
+	values := make([]string, 0)
+
+	ctx, cancel := context.WithTimeout(e.client.Ctx(), EtcdRequestTimeout)
+	defer cancel()
+
+	resp, err := e.client.Get(ctx, prefix, clientv3.WithPrefix(),
+		clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend))
+	if err != nil {
+		return values, err
+	}
+
+	for _, kv := range resp.Kvs {
+		values = append(values, string(kv.Value))
+	}
+	return values, nil
+}
+
+func (e *etcdCoordinator) Put(key, value string, ephemeral bool) error {
+	var opts []clientv3.OpOption
+	if ephemeral {
+		s, err := e.getSession()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, clientv3.WithLease(s.Lease()))
+	}
+
+	ctx, cancel := context.WithTimeout(e.client.Ctx(), EtcdRequestTimeout)
+	defer cancel()
+
+	_, err := e.client.Put(ctx, key, value, opts...)
+	return err
+}
+
+func (e *etcdCoordinator) Create(key, value string) (bool, error) {
+	s, err := e.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(e.client.Ctx(), EtcdRequestTimeout)
+	defer cancel()
+
+	resp, err := e.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, value, clientv3.WithLease(s.Lease()))).
+		Else(clientv3.OpGet(key)).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+	if resp.Succeeded {
+		return true, nil
+	}
+
+	kvs := resp.Responses[0].GetResponseRange().Kvs
+	return len(kvs) > 0 && clientv3.LeaseID(kvs[0].Lease) == s.Lease(), nil
+}
+
+// leader election can take some time to wait ttl expires, it blocks until
+// we got the leadership, the loss of it will be reported as LeadershipLost
+func (e *etcdCoordinator) Campaign(election, value string) error {
+	for {
+		s, err := e.getSession()
+		if err != nil {
+			log.Printf("Failed to create session: %s", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		// stop waiting for the candidates ahead of us once the session is gone
+		ctx, cancel := context.WithCancel(e.client.Ctx())
+		go func() {
+			select {
+			case <-s.Done():
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		el := concurrency.NewElection(s, election)
+		err = el.Campaign(ctx, value)
+		cancel()
+		if err != nil {
+			log.Printf("Failed to campaign: %s", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		log.Printf("leader path: %s", el.Key())
+
+		e.mutex.Lock()
+		if e.session != s {
+			// lost the session while waiting
+			e.mutex.Unlock()
+			continue
+		}
+		e.election = el
+		e.resigned = make(chan struct{})
+		go e.watchLeadership(el, e.resigned)
+		e.mutex.Unlock()
+
+		return nil
+	}
+}
+
+// dropLeadership clears the leadership and stops the watcher, mutex needs to be held
+func (e *etcdCoordinator) dropLeadership() *concurrency.Election {
+	el := e.election
+	if el != nil {
+		e.election = nil
+		close(e.resigned)
+	}
+	return el
+}
+
+// watchLeadership watches the leader key from the revision it got created
+func (e *etcdCoordinator) watchLeadership(el *concurrency.Election, resigned chan struct{}) {
+	key := el.Key()
+	rev := el.Rev()
+
+	ctx, cancel := context.WithCancel(e.client.Ctx())
+	defer cancel()
+	go func() {
+		<-resigned
+		cancel()
+	}()
+
+	var err error
+	for {
+		deleted := false
+		for resp := range e.client.Watch(ctx, key, clientv3.WithRev(rev+1)) {
+			if err = resp.Err(); err != nil {
+				break
+			}
+			for _, ev := range resp.Events {
+				rev = ev.Kv.ModRevision
+				if ev.Type == mvccpb.DELETE {
+					deleted = true
+				}
+			}
+			if deleted {
+				break
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if deleted {
+			err = fmt.Errorf("leader key %s removed", key)
+			break
+		}
+
+		// the watch got broken, retry from the last revision we've seen
+		log.Printf("Watch on %s broken: %v", key, err)
+		time.Sleep(time.Second)
+	}
+
+	e.mutex.Lock()
+	leader := e.election == el
+	if leader {
+		e.dropLeadership()
+	}
+	e.mutex.Unlock()
+
+	if leader {
+		e.events.emit(LeadershipLost, err)
+	}
+}
+
+// Resign deletes our candidate key so a standby can take over immediately
+func (e *etcdCoordinator) Resign() error {
+	e.mutex.Lock()
+	el := e.dropLeadership()
+	e.mutex.Unlock()
+
+	if el == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(e.client.Ctx(), EtcdRequestTimeout)
+	defer cancel()
+	return el.Resign(ctx)
+}
+
+func (e *etcdCoordinator) Watch(prefix string, changed chan<- string, stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(e.client.Ctx())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for resp := range e.client.Watch(ctx, prefix, clientv3.WithPrefix()) {
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range resp.Events {
+			select {
+			case changed <- string(ev.Kv.Key):
+			case <-stop:
+			}
+		}
+	}
+
+	// stopped by the caller or the client got closed
+	return nil
+}
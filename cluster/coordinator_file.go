@@ -0,0 +1,334 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	FilePollInterval = time.Second
+	// first line of the ephemeral keys
+	fileSessionMark = "#session\n"
+)
+
+// fileCoordinator keeps the keys as files under a local directory, it's
+// meant for a single host and tests. The session is the process itself,
+// an ephemeral key is a file we hold the flock of, the file is kept in place
+// when the process died but treated as gone once its lock got released.
+// The leader is the one holding the lock of the election file.
+type fileCoordinator struct {
+	dir      string
+	events   *eventLatch
+	mutex    sync.Mutex
+	locked   map[string]*os.File
+	election string
+}
+
+func newFileCoordinator(dir string) (*fileCoordinator, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	locked := make(map[string]*os.File)
+	return &fileCoordinator{dir: dir, events: newEventLatch(), locked: locked}, nil
+}
+
+func (f *fileCoordinator) path(key string) string {
+	return filepath.Join(f.dir, filepath.FromSlash(key))
+}
+
+// the session never expires while we're running
+func (f *fileCoordinator) Events() <-chan Event {
+	return f.events.events
+}
+
+func (f *fileCoordinator) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for key, file := range f.locked {
+		file.Close()
+		delete(f.locked, key)
+	}
+	f.election = ""
+	return nil
+}
+
+// alive checks if the lock of an ephemeral key is still held by someone
+func alive(name string) bool {
+	file, err := os.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	return err == syscall.EWOULDBLOCK
+}
+
+// read returns the value of the key file, ephemeral keys without an owner
+// are reported as not found
+func (f *fileCoordinator) read(name string) (string, error) {
+	data, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return "", ErrKeyNotFound
+	} else if err != nil {
+		return "", err
+	}
+
+	value := string(data)
+	if strings.HasPrefix(value, fileSessionMark) {
+		if !alive(name) {
+			return "", ErrKeyNotFound
+		}
+		value = strings.TrimPrefix(value, fileSessionMark)
+	}
+	return value, nil
+}
+
+func (f *fileCoordinator) Get(key string) (string, error) {
+	return f.read(f.path(key))
+}
+
+// List returns the values in modified order, files don't have a portable
+// creation time
+func (f *fileCoordinator) List(prefix string) ([]string, error) {
+	values := make([]string, 0)
+
+	infos, err := ioutil.ReadDir(f.path(prefix))
+	if os.IsNotExist(err) {
+		return values, nil
+	} else if err != nil {
+		return values, err
+	}
+
+	sort.Sort(byModTime(infos))
+	for _, info := range infos {
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+
+		value, err := f.read(filepath.Join(f.path(prefix), info.Name()))
+		if err == ErrKeyNotFound {
+			continue
+		} else if err != nil {
+			return values, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// write replaces the content of a key file we hold the lock of
+func write(file *os.File, value string) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.WriteAt([]byte(fileSessionMark+value), 0)
+	return err
+}
+
+// lock opens the key file and takes its lock, it blocks until we got the
+// lock if wait is set, otherwise nil is returned if someone else holds it
+func (f *fileCoordinator) lock(key string, wait bool) (*os.File, error) {
+	name := f.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	how := syscall.LOCK_EX
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	if err := syscall.Flock(int(file.Fd()), how); err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+func (f *fileCoordinator) Create(key, value string) (bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, ok := f.locked[key]; ok {
+		return true, nil
+	}
+
+	file, err := f.lock(key, false)
+	if err != nil || file == nil {
+		return false, err
+	}
+
+	if err := write(file, value); err != nil {
+		file.Close()
+		return false, err
+	}
+	f.locked[key] = file
+	return true, nil
+}
+
+func (f *fileCoordinator) Put(key, value string, ephemeral bool) error {
+	if !ephemeral {
+		// write to a hidden file and rename, so readers never see a partial value
+		name := f.path(key)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+
+		tmp := filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".tmp")
+		if err := ioutil.WriteFile(tmp, []byte(value), 0644); err != nil {
+			return err
+		}
+		return os.Rename(tmp, name)
+	}
+
+	f.mutex.Lock()
+	file, ok := f.locked[key]
+	f.mutex.Unlock()
+
+	if ok {
+		return write(file, value)
+	}
+
+	owned, err := f.Create(key, value)
+	if err == nil && !owned {
+		err = fmt.Errorf("%s is held by another session", key)
+	}
+	return err
+}
+
+// Campaign waits for the lock of the election file, the file is never
+// removed so all the candidates are queued on the same lock
+func (f *fileCoordinator) Campaign(election, value string) error {
+	file, err := f.lock(election, true)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file, value); err != nil {
+		file.Close()
+		return err
+	}
+
+	f.mutex.Lock()
+	f.locked[election] = file
+	f.election = election
+	f.mutex.Unlock()
+	return nil
+}
+
+func (f *fileCoordinator) Resign() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.election == "" {
+		return nil
+	}
+
+	file := f.locked[f.election]
+	delete(f.locked, f.election)
+	f.election = ""
+	return file.Close()
+}
+
+// fileState is what we compare to find out the changed keys
+type fileState struct {
+	modTime time.Time
+	size    int64
+	alive   bool
+}
+
+func (f *fileCoordinator) scan(prefix string) map[string]fileState {
+	states := make(map[string]fileState)
+
+	root := f.path(prefix)
+	filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(f.dir, name)
+		if err != nil {
+			return nil
+		}
+
+		// the owner of an ephemeral key might be gone without touching the file
+		_, err = f.read(name)
+		states[filepath.ToSlash(rel)] = fileState{info.ModTime(), info.Size(), err == nil}
+		return nil
+	})
+	return states
+}
+
+// Watch polls the directory and notifies the keys got modified, removed
+// or lost their owner
+func (f *fileCoordinator) Watch(prefix string, changed chan<- string, stop <-chan struct{}) error {
+	states := f.scan(prefix)
+
+	ticker := time.NewTicker(FilePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return nil
+		}
+
+		current := f.scan(prefix)
+		keys := make([]string, 0)
+		for key, state := range current {
+			if old, ok := states[key]; !ok || old != state {
+				keys = append(keys, key)
+			}
+		}
+		for key := range states {
+			if _, ok := current[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		states = current
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			select {
+			case changed <- key:
+			case <-stop:
+				return nil
+			}
+		}
+	}
+}
+
+type byModTime []os.FileInfo
+
+func (infos byModTime) Len() int {
+	return len(infos)
+}
+
+func (infos byModTime) Less(i, j int) bool {
+	return infos[i].ModTime().Before(infos[j].ModTime())
+}
+
+func (infos byModTime) Swap(i, j int) {
+	infos[i], infos[j] = infos[j], infos[i]
+}
@@ -0,0 +1,119 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func tempCoordinatorDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "coordinator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestFileRegister(t *testing.T) {
+	dir := tempCoordinatorDir(t)
+	defer os.RemoveAll(dir)
+
+	c1 := newTestClient(t, "file://"+dir, "10.0.0.1")
+	if err := c1.Register(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c1.Register(); err != nil {
+		t.Fatal(err)
+	}
+
+	c2 := newTestClient(t, "file://"+dir, "10.0.0.1")
+	c2.Pid = "1"
+	if err := c2.Register(); err == nil {
+		t.Fatal("expected the second instance on the same node to be refused")
+	}
+
+	// the node is gone along with the session
+	c1.Close()
+	if _, err := c2.FindInstance(); err != ErrKeyNotFound {
+		t.Fatalf("expected the node to be gone, got %v", err)
+	}
+}
+
+func TestFileElection(t *testing.T) {
+	dir := tempCoordinatorDir(t)
+	defer os.RemoveAll(dir)
+
+	c1 := newTestClient(t, "file://"+dir, "10.0.0.1")
+	c2 := newTestClient(t, "file://"+dir, "10.0.0.2")
+
+	if err := c1.BecomeLeader(); err != nil {
+		t.Fatal(err)
+	}
+
+	elected := make(chan error, 1)
+	go func() {
+		elected <- c2.BecomeLeader()
+	}()
+
+	select {
+	case <-elected:
+		t.Fatal("two leaders elected")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	if err := c1.Resign(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-elected:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("standby didn't take over the leadership")
+	}
+}
+
+func TestFileWatchConfig(t *testing.T) {
+	dir := tempCoordinatorDir(t)
+	defer os.RemoveAll(dir)
+
+	c := newTestClient(t, "file://"+dir, "10.0.0.1")
+
+	changed := make(chan struct{}, 4)
+	stop := make(chan bool)
+	go c.WatchConfig(changed, stop)
+	defer close(stop)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := c.PublishHealth(map[string]*BackendHealth{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddResource("10.1.0.1:3306"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the resource change")
+	}
+
+	select {
+	case <-changed:
+		t.Fatal("health update shouldn't be reported as config change")
+	case <-time.After(2 * FilePollInterval):
+	}
+
+	views, err := c.FleetHealth()
+	if err != nil || len(views) != 1 || views[0].Node != "10.0.0.1" {
+		t.Errorf("unexpected health views %v, %v", views, err)
+	}
+}
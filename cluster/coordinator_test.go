@@ -0,0 +1,44 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package cluster
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEventLatch(t *testing.T) {
+	l := newEventLatch()
+
+	// nobody reads for a while
+	for i := 0; i < 100; i++ {
+		l.emit(LeadershipLost, errors.New("leader"))
+		l.emit(LeaseLost, errors.New("lease"))
+	}
+	l.emit(LeaseLost, errors.New("last"))
+
+	got := make([]EventType, 0)
+	var last error
+	for done := false; !done; {
+		select {
+		case ev := <-l.events:
+			got = append(got, ev.Type)
+			if ev.Type == LeaseLost {
+				last = ev.Err
+			}
+		case <-time.After(100 * time.Millisecond):
+			done = true
+		}
+	}
+
+	// the first one might be on its way already, the others are merged
+	if len(got) < 2 || len(got) > 3 {
+		t.Fatalf("unexpected events %v", got)
+	}
+	if last == nil || last.Error() != "last" {
+		t.Errorf("expected the last lease loss, got %v", last)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package cluster
+
+import (
+	"context"
+	"github.com/zhgwenming/gbalancer/Godeps/_workspace/src/go.etcd.io/etcd/server/v3/embed"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// freeURL returns a http url on a free local port
+func freeURL(t *testing.T) url.URL {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return url.URL{Scheme: "http", Host: l.Addr().String()}
+}
+
+// startEtcd runs a single member etcd in process, returns its client url
+func startEtcd(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "etcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := embed.NewConfig()
+	cfg.Dir = dir
+	cfg.LogLevel = "error"
+	cfg.ListenClientUrls = []url.URL{freeURL(t)}
+	cfg.AdvertiseClientUrls = cfg.ListenClientUrls
+	cfg.ListenPeerUrls = []url.URL{freeURL(t)}
+	cfg.AdvertisePeerUrls = cfg.ListenPeerUrls
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		e.Close()
+		os.RemoveAll(dir)
+	})
+
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("etcd didn't get ready")
+	}
+	return cfg.ListenClientUrls[0].String()
+}
+
+func newTestClient(t *testing.T, endpoint, ip string) *Client {
+	c, err := NewClient("test", "cluster1", []string{endpoint}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.IPAddress = ip
+	return c
+}
+
+func waitEvent(t *testing.T, c *Client, expected EventType) {
+	select {
+	case ev := <-c.Events():
+		if ev.Type != expected {
+			t.Fatalf("expected %s, got %s", expected, ev.Type)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for %s", expected)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	endpoint := startEtcd(t)
+
+	c1 := newTestClient(t, endpoint, "10.0.0.1")
+	if err := c1.Register(); err != nil {
+		t.Fatal(err)
+	}
+
+	// registering twice is harmless
+	if err := c1.Register(); err != nil {
+		t.Fatal(err)
+	}
+
+	// another running instance on the same node
+	c2 := newTestClient(t, endpoint, "10.0.0.1")
+	c2.Pid = "1"
+	if err := c2.Register(); err == nil {
+		t.Fatal("expected the second instance on the same node to be refused")
+	}
+}
+
+func TestElection(t *testing.T) {
+	endpoint := startEtcd(t)
+
+	c1 := newTestClient(t, endpoint, "10.0.0.1")
+	c2 := newTestClient(t, endpoint, "10.0.0.2")
+
+	if err := c1.BecomeLeader(); err != nil {
+		t.Fatal(err)
+	}
+
+	elected := make(chan error, 1)
+	go func() {
+		elected <- c2.BecomeLeader()
+	}()
+
+	select {
+	case <-elected:
+		t.Fatal("two leaders elected")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	if err := c1.Resign(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-elected:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("standby didn't take over the leadership")
+	}
+
+	// resigned on purpose, no event expected
+	select {
+	case ev := <-c1.Events():
+		t.Fatalf("unexpected event %s", ev.Type)
+	default:
+	}
+}
+
+func TestLeaseLost(t *testing.T) {
+	endpoint := startEtcd(t)
+
+	c := newTestClient(t, endpoint, "10.0.0.1")
+	if err := c.BecomeLeader(); err != nil {
+		t.Fatal(err)
+	}
+
+	// expire the session on the server side
+	e := c.coord.(*etcdCoordinator)
+	e.mutex.Lock()
+	lease := e.session.Lease()
+	e.mutex.Unlock()
+	if _, err := e.client.Revoke(context.Background(), lease); err != nil {
+		t.Fatal(err)
+	}
+
+	waitEvent(t, c, LeadershipLost)
+	waitEvent(t, c, LeaseLost)
+}
+
+func TestWatchConfig(t *testing.T) {
+	endpoint := startEtcd(t)
+
+	c := newTestClient(t, endpoint, "10.0.0.1")
+
+	changed := make(chan struct{}, 4)
+	stop := make(chan bool)
+	go c.WatchConfig(changed, stop)
+	defer close(stop)
+
+	// let the watcher to be created
+	time.Sleep(100 * time.Millisecond)
+
+	if err := c.PublishHealth(map[string]*BackendHealth{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := c.AddResource("10.1.0." + strconv.Itoa(i) + ":3306"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the resource changes")
+		}
+	}
+
+	select {
+	case <-changed:
+		t.Fatal("health update shouldn't be reported as config change")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	resources, err := c.Resources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 2 || resources[0] != "10.1.0.1:3306" {
+		t.Errorf("unexpected resources %v", resources)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package cluster
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"time"
+)
+
+// backend state observed by a node
+type BackendHealth struct {
+	Up      bool       `json:"up"`
+	Error   string     `json:"error,omitempty"`
+	Active  uint       `json:"active"`
+	Ejected *time.Time `json:"ejected,omitempty"` // kept out by the engine until
+}
+
+// health view published by each node under health/{ip}
+type NodeHealth struct {
+	Node     string                    `json:"node"`
+	Pid      string                    `json:"pid"`
+	Time     time.Time                 `json:"time"`
+	Backends map[string]*BackendHealth `json:"backends"`
+}
+
+// backend seen in different states by the nodes
+type Disagreement struct {
+	Backend string
+	Up      []string
+	Down    map[string]string // node -> probe error
+}
+
+func (l *Client) HealthPath() string {
+	return path.Join(l.Prefix(), "health", l.IPAddress)
+}
+
+// PublishHealth publishes our view of the backends, it expires along
+// with our session
+func (l *Client) PublishHealth(backends map[string]*BackendHealth) error {
+	health := &NodeHealth{l.IPAddress, l.Pid, time.Now(), backends}
+
+	data, err := json.Marshal(health)
+	if err != nil {
+		return err
+	}
+
+	return l.coord.Put(l.HealthPath(), string(data), true)
+}
+
+// FleetHealth returns the views published by all the nodes of the cluster
+func (l *Client) FleetHealth() ([]*NodeHealth, error) {
+	views := make([]*NodeHealth, 0)
+
+	values, err := l.coord.List(path.Dir(l.HealthPath()) + "/")
+	if err != nil {
+		return views, err
+	}
+
+	for _, value := range values {
+		health := &NodeHealth{}
+		if err := json.Unmarshal([]byte(value), health); err != nil {
+			log.Printf("Bad health view %q: %s", value, err)
+			continue
+		}
+		views = append(views, health)
+	}
+	return views, nil
+}
+
+// Disagreements compares the views and returns the backends which are not
+// seen in the same state by all the nodes, a backend missing in a view
+// is treated as down on that node
+func Disagreements(views []*NodeHealth) []*Disagreement {
+	backends := make(map[string]bool)
+	for _, v := range views {
+		for addr := range v.Backends {
+			backends[addr] = true
+		}
+	}
+
+	addrs := make([]string, 0, len(backends))
+	for addr := range backends {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	result := make([]*Disagreement, 0)
+	for _, addr := range addrs {
+		d := &Disagreement{addr, make([]string, 0), make(map[string]string)}
+		for _, v := range views {
+			if b, ok := v.Backends[addr]; !ok {
+				d.Down[v.Node] = "not seen"
+			} else if b.Up {
+				d.Up = append(d.Up, v.Node)
+			} else {
+				d.Down[v.Node] = b.Error
+			}
+		}
+
+		if len(d.Up) > 0 && len(d.Down) > 0 {
+			result = append(result, d)
+		}
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package cluster
+
+import (
+	"testing"
+)
+
+func TestDisagreements(t *testing.T) {
+	views := []*NodeHealth{
+		{Node: "10.0.0.1", Backends: map[string]*BackendHealth{
+			"10.1.0.1:3306": {Up: true},
+			"10.1.0.2:3306": {Up: true},
+			"10.1.0.3:3306": {Up: false, Error: "Galera Not Connected"},
+		}},
+		{Node: "10.0.0.2", Backends: map[string]*BackendHealth{
+			"10.1.0.1:3306": {Up: true},
+			"10.1.0.2:3306": {Up: false, Error: "i/o timeout"},
+			"10.1.0.3:3306": {Up: false, Error: "Galera Not Connected"},
+		}},
+		{Node: "10.0.0.3", Backends: map[string]*BackendHealth{
+			"10.1.0.2:3306": {Up: true},
+			"10.1.0.3:3306": {Up: false},
+		}},
+	}
+
+	result := Disagreements(views)
+	if len(result) != 2 {
+		t.Fatalf("expected 2 disagreements, got %d", len(result))
+	}
+
+	d := result[0]
+	if d.Backend != "10.1.0.1:3306" || len(d.Up) != 2 || d.Down["10.0.0.3"] != "not seen" {
+		t.Errorf("unexpected disagreement on missing backend: %+v", d)
+	}
+
+	d = result[1]
+	if d.Backend != "10.1.0.2:3306" || len(d.Up) != 2 || d.Down["10.0.0.2"] != "i/o timeout" {
+		t.Errorf("unexpected disagreement on down backend: %+v", d)
+	}
+}
@@ -7,7 +7,14 @@ package main
 import (
 	"flag"
 	"github.com/zhgwenming/gbalancer/cluster"
+	"github.com/zhgwenming/gbalancer/config"
+	"github.com/zhgwenming/gbalancer/engine/ipvs"
+	"github.com/zhgwenming/gbalancer/wrangler"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 )
 
@@ -18,18 +25,159 @@ const (
 
 var (
 	clusterName = flag.String("cluster", "clusterService1", "Cluster name")
+	etcdConfig  = flag.String("etcd", "config.json", "coordinator client configuration file")
+	configFile  = flag.String("config", "/etc/gbalancer/gbalancer.json", "Configuration file")
+	vipAddr     = flag.String("vip", "", "virtual ip owned by the leader")
+	vipIface    = flag.String("iface", "eth0", "interface to bind the virtual ip")
+	sigChan     = make(chan os.Signal, 1)
 )
 
+func init() {
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+}
+
+func copyBackends(backends map[string]int) map[string]int {
+	dst := make(map[string]int, len(backends))
+	for addr, f := range backends {
+		dst[addr] = f
+	}
+	return dst
+}
+
+// mergeBackends applies the changes from the wrangler, FlagDown takes a backend out
+func mergeBackends(backends, changes map[string]int) {
+	for addr, f := range changes {
+		if f == wrangler.FlagDown {
+			delete(backends, addr)
+		} else {
+			backends[addr] = f
+		}
+	}
+}
+
+func elect(director *cluster.Client) <-chan error {
+	elected := make(chan error, 1)
+	go func() {
+		elected <- director.BecomeLeader()
+	}()
+	return elected
+}
+
+// lead owns the vip and runs the ipvs service until the leadership got lost
+// or we got an exit signal, returns false if we need to exit
+func lead(director *cluster.Client, vip *ipvs.VirtualIP, port string,
+	status <-chan map[string]int, backends map[string]int) (map[string]int, bool) {
+
+	if err := vip.Up(); err != nil {
+		log.Printf("Failed to take over vip %s, resigning: %s", vip.Addr, err)
+		director.Resign()
+		time.Sleep(ttl * time.Second)
+		return backends, true
+	}
+
+	done := make(chan struct{})
+	wgroup := &sync.WaitGroup{}
+	ipvsStatus := make(chan map[string]int, wrangler.MaxBackends)
+
+	wgroup.Add(1)
+	lvs := ipvs.NewIPvs(vip.Addr, port, "wlc", done, wgroup)
+	go lvs.RemoteSchedule(ipvsStatus)
+
+	// the wrangler only reports the changes, feed the last known backends
+	if len(backends) > 0 {
+		ipvsStatus <- copyBackends(backends)
+	}
+
+	running := true
+	for leading := true; leading; {
+		select {
+		case changes := <-status:
+			mergeBackends(backends, changes)
+			ipvsStatus <- changes
+		case ev := <-director.Events():
+			log.Printf("Got %s, stepping down", ev.Type)
+			leading = false
+		case sig := <-sigChan:
+			log.Printf("captured %v, exiting..", sig)
+			leading = false
+			running = false
+		}
+	}
+
+	// stop the ipvs service and release the vip so a standby can take over
+	close(done)
+	wgroup.Wait()
+
+	if err := vip.Down(); err != nil {
+		log.Printf("Failed to release vip %s: %s", vip.Addr, err)
+	}
+
+	if !running {
+		if err := director.Resign(); err != nil {
+			log.Printf("Failed to resign the leadership: %s", err)
+		}
+		director.Close()
+	}
+
+	return backends, running
+}
+
 func main() {
-	director := cluster.NewClientFromFile(ServiceName, *clusterName, "config.json")
+	flag.Parse()
+
+	if *vipAddr == "" {
+		log.Fatal("Need to specify the virtual ip with -vip")
+	}
+
+	settings, err := config.LoadConfig(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	director, err := cluster.NewClientFromFile(ServiceName, *clusterName, *etcdConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	log.Printf("Starting with node: %s", director.NodePath())
-	if err := director.Register(ttl); err != nil {
+	if err := director.Register(); err != nil {
 		log.Fatal(err)
 	}
 
-	director.BecomeLeader(ttl)
-	for {
-		time.Sleep(time.Second)
+	// standby nodes keep probing as well to have the backends ready
+	status := make(chan map[string]int, wrangler.MaxBackends)
+	wgl, err := wrangler.NewWrangler(settings, status)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go wgl.Monitor()
+
+	vip := ipvs.NewVirtualIP(*vipAddr, *vipIface)
+
+	backends := make(map[string]int, wrangler.MaxBackends)
+	for running := true; running; {
+		elected := elect(director)
+	standby:
+		for {
+			select {
+			case changes := <-status:
+				mergeBackends(backends, changes)
+			case <-elected:
+				break standby
+			case ev := <-director.Events():
+				// our node got expired along with the lease
+				if ev.Type == cluster.LeaseLost {
+					if err := director.Register(); err != nil {
+						log.Fatal(err)
+					}
+				}
+			case sig := <-sigChan:
+				log.Printf("captured %v, exiting..", sig)
+				director.Close()
+				return
+			}
+		}
+
+		backends, running = lead(director, vip, settings.Port, status, backends)
 	}
 }
@@ -6,6 +6,7 @@ package main
 
 import (
 	"github.com/zhgwenming/gbalancer/Godeps/_workspace/src/github.com/docker/spdystream"
+	"github.com/zhgwenming/gbalancer/proxyproto"
 	"io"
 	"net"
 	"net/http"
@@ -39,6 +40,19 @@ func AgentStreamHandler(stream *spdystream.Stream) {
 		return
 	}
 
+	// pass the client address from gbalancer to the service
+	if proxy, err := proxyproto.FromHTTPHeader(stream.Headers()); err != nil {
+		log.Printf("bad PROXY header: %s\n", err)
+		conn.Close()
+		return
+	} else if proxy != nil {
+		if _, err := proxy.WriteTo(conn); err != nil {
+			log.Printf("Failed to send PROXY header: %s\n", err)
+			conn.Close()
+			return
+		}
+	}
+
 	replyErr := stream.SendReply(http.Header{}, false)
 	if replyErr != nil {
 		return
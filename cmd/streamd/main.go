@@ -22,7 +22,7 @@ var (
 	pidFile     = flag.String("pidfile", "", "pid file")
 	listenAddr  = flag.String("listen", ":6900", "port number")
 	serviceAddr = flag.String("to", "/var/lib/mysql/mysql.sock", "service address")
-	log         = logger.NewLogger()
+	log         = logger.New("streamd")
 	sigChan     = make(chan os.Signal, 1)
 	wgroup      = &sync.WaitGroup{}
 )
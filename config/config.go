@@ -7,6 +7,8 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/zhgwenming/gbalancer/proxyproto"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -52,15 +54,238 @@ func LoadConfig(configFile string) (*Configuration, error) {
 	return config, err
 }
 
+// LoadSharedConfig parses the shared configuration stored in etcd
+func LoadSharedConfig(data string) (*Configuration, error) {
+	config := &Configuration{}
+	err := json.Unmarshal([]byte(data), config)
+	return config, err
+}
+
 type Configuration struct {
-	Service    string
-	ExtCommand string
-	User       string
-	Pass       string
-	Addr       string
-	Port       string
-	Listen     []string
-	Backend    []string
+	Service     string
+	ExtCommand  string
+	User        string
+	Pass        string
+	Addr        string
+	Port        string
+	Listen      []string
+	Backend     []string
+	Etcd        []string
+	Coordinator []string
+	Cluster     string
+	Publish     bool
+	Log         LogConfig
+	// PROXY protocol version sent to the backends, "*" for the default
+	SendProxy map[string]string
+	// certificates of the listeners with the tls option and TLS towards
+	// the backends, by address with "*" for the default
+	TLS        map[string]TLSConfig
+	BackendTLS map[string]BackendTLSConfig
+	MySQL      MySQLConfig
+	HTTPCheck  HTTPCheckConfig
+	Replica    ReplicaConfig
+	Ext        ExtConfig
+	Galera     GaleraConfig
+	// the health checks of the backends which aren't done on the service
+	// address or with the driver of the service, "*" for the default
+	Check   map[string]CheckConfig
+	Health  HealthConfig
+	Outlier OutlierConfig
+}
+
+// OutlierConfig ejects the backends failing the live connections of the
+// native engine, after ConsecutiveFailures failed connections in a row or
+// once ErrorRatio of the connections of an Interval ("10s") failed, with at
+// least MinConnections (10) of them. The connections failing on dial, and
+// the ones the backend resets within ResetWithin of the dial, are failed.
+// A backend is ejected for BaseEjection ("30s"), doubled on every ejection
+// up to MaxEjection ("5m"), and no more than MaxEjectedPercent (50) of the
+// backends are ejected at a time.
+type OutlierConfig struct {
+	ConsecutiveFailures int
+	ErrorRatio          float64
+	MinConnections      int
+	Interval            string
+	ResetWithin         string
+	BaseEjection        string
+	MaxEjection         string
+	MaxEjectedPercent   int
+}
+
+// HealthConfig is the schedule of the checks, the backends are checked
+// every Interval ("60s" by default), or every FailInterval ("5s") while
+// they're down
+type HealthConfig struct {
+	Interval     string
+	FailInterval string
+}
+
+// CheckConfig probes the backend on Addr, or on Port of the backend host,
+// with the Type driver, the empty ones are the ones of the service
+type CheckConfig struct {
+	Addr string
+	Port string
+	Type string
+}
+
+// CheckTarget returns the check address and driver of a backend
+func (c *Configuration) CheckTarget(backend string) (string, string, error) {
+	check, ok := c.Check[backend]
+	if !ok {
+		check = c.Check["*"]
+	}
+
+	addr, typ := backend, c.Service
+	if check.Type != "" {
+		typ = check.Type
+	}
+
+	switch {
+	case check.Addr != "":
+		addr = check.Addr
+	case check.Port != "":
+		host, _, err := net.SplitHostPort(backend)
+		if err != nil {
+			return "", "", fmt.Errorf("backend %s: %s", backend, err)
+		}
+		addr = net.JoinHostPort(host, check.Port)
+	}
+	return addr, typ, nil
+}
+
+// HTTPCheckConfig is the probe of the http service. Status lists the
+// healthy status codes like "200-299,301", the body must contain Body and
+// match BodyRegexp if they're set. CA and InsecureSkipVerify apply to
+// https, and the Host header is the server name of the TLS as well.
+type HTTPCheckConfig struct {
+	Path               string
+	Method             string
+	Host               string
+	Status             string
+	Body               string
+	BodyRegexp         string
+	HTTPS              bool
+	CA                 string
+	InsecureSkipVerify bool
+	Timeout            string
+}
+
+// ExtConfig is the check of the ext service, ExtCommand is killed along
+// with its children after Timeout ("10s" by default), Env is added to the
+// environment of it
+type ExtConfig struct {
+	Timeout string
+	Env     map[string]string
+}
+
+// ReplicaConfig is the check of the mysql-replica, postgres and redis
+// services, the replicas lagging more than MaxLag ("30s" by default) behind
+// the primary are down, or the redis ones with the link to the master down
+// for longer. Database is the one the postgres check connects to.
+type ReplicaConfig struct {
+	MaxLag   string
+	Database string
+}
+
+// GaleraConfig translates the nodes found in wsrep_incoming_addresses to
+// the addresses gbalancer dials. Nodes maps the wsrep_node_name of a node to
+// its address, Addresses rewrites a reported "host:port" or host, the port
+// is kept when the new address has none.
+type GaleraConfig struct {
+	Nodes     map[string]string
+	Addresses map[string]string
+}
+
+// MySQLConfig enables the protocol aware mode of the native engine, the
+// clients log in with the Users which the backends must accept as well.
+// ServerVersion is announced to the clients instead of the backend one.
+//
+// NoBackend and Busy are the errors the clients get after the handshake
+// when no backend is available or all of them are busy, they're sent for
+// the galera and mysql-replica services, the protocol aware mode or once
+// NoBackend is set.
+type MySQLConfig struct {
+	Split         bool
+	Users         map[string]string
+	ServerVersion string
+	NoBackend     MySQLError
+	Busy          MySQLError
+	Pool          MySQLPool
+}
+
+// MySQLPool keeps up to MaxIdle authenticated connections per backend for
+// the next clients of the same user and database, MaxConnections limits
+// the connections to a backend.
+type MySQLPool struct {
+	MaxIdle        int
+	MaxConnections int
+	IdleTimeout    string
+}
+
+type MySQLError struct {
+	Code    uint16
+	Message string
+}
+
+// Rejects tells whether the clients get the MySQL errors
+func (c *Configuration) Rejects() bool {
+	return c.Service == "galera" || c.Service == "mysql-replica" || c.MySQLAware() || c.MySQL.NoBackend.Code != 0
+}
+
+// MySQLAware tells whether the native engine speaks the MySQL protocol
+func (c *Configuration) MySQLAware() bool {
+	m := &c.MySQL
+	return m.Split || m.Pool.MaxIdle > 0 || m.Pool.MaxConnections > 0
+}
+
+// LogConfig is reapplied on SIGUSR1, Output is "syslog", "stderr" or the
+// path of a log file, empty keeps syslog with a fallback to stderr
+type LogConfig struct {
+	Level    string
+	Output   string
+	JSON     bool
+	Facility string
+	Tag      string
+}
+
+// CoordinatorEndpoints returns the endpoints of the coordination service,
+// etcd is kept for the configs which predate the other coordinators
+func (c *Configuration) CoordinatorEndpoints() []string {
+	if len(c.Coordinator) > 0 {
+		return c.Coordinator
+	}
+	return c.Etcd
+}
+
+// Merge overrides the service settings with the shared ones,
+// the listen settings are local and always kept
+func (c *Configuration) Merge(shared *Configuration) {
+	if shared.Service != "" {
+		c.Service = shared.Service
+	}
+	if shared.ExtCommand != "" {
+		c.ExtCommand = shared.ExtCommand
+	}
+	if shared.User != "" {
+		c.User = shared.User
+	}
+	if shared.Pass != "" {
+		c.Pass = shared.Pass
+	}
+}
+
+// ProxyVersions returns the PROXY protocol version of the backends, the
+// one of "*" applies to the backends not listed
+func (c *Configuration) ProxyVersions() (map[string]int, error) {
+	versions := make(map[string]int, len(c.SendProxy))
+	for backend, v := range c.SendProxy {
+		version, err := proxyproto.ParseVersion(v)
+		if err != nil {
+			return nil, fmt.Errorf("backend %s: %s", backend, err)
+		}
+		versions[backend] = version
+	}
+	return versions, nil
 }
 
 func (c *Configuration) ListenInfo() string {
@@ -82,6 +307,29 @@ func (c *Configuration) GetListenAddrs() ([]ListenAddr, error) {
 
 		net, laddr := protoAddrParts[0], protoAddrParts[1]
 
+		// tcp://0.0.0.0:3306?proxy accepts the PROXY protocol,
+		// tcp://0.0.0.0:3306?proxy&tls terminates TLS as well,
+		// tcp://0.0.0.0:3307?replica only forwards to the replicas
+		var proxy, tls bool
+		var role string
+		if i := strings.LastIndex(laddr, "?"); i != -1 {
+			for _, option := range strings.Split(laddr[i+1:], "&") {
+				switch option {
+				case "proxy":
+					proxy = true
+				case "tls":
+					tls = true
+				case "primary", "replica":
+					role = option
+				case "standby":
+					role = "replica"
+				default:
+					return laddrs, fmt.Errorf("unknown listen option %s", l)
+				}
+			}
+			laddr = laddr[:i]
+		}
+
 		var addr ListenAddr
 		if net == "unix" {
 			// unix://default form
@@ -90,7 +338,7 @@ func (c *Configuration) GetListenAddrs() ([]ListenAddr, error) {
 			}
 		}
 
-		addr = ListenAddr{net, laddr}
+		addr = ListenAddr{net, laddr, proxy, tls, role}
 
 		laddrs = append(laddrs, addr)
 	}
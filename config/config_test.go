@@ -0,0 +1,60 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package config
+
+import (
+	"testing"
+)
+
+func TestCheckTarget(t *testing.T) {
+	c := &Configuration{
+		Service: "galera",
+		Check: map[string]CheckConfig{
+			"10.0.0.1:3306":   {Addr: "10.0.0.1:9200", Type: "http"},
+			"10.0.0.2:3306":   {Port: "9200"},
+			"[fe80::1]:3306":  {Port: "9200", Type: "tcp"},
+			"10.0.0.3:3306":   {Type: "tcp"},
+			"/tmp/mysql.sock": {Port: "9200"},
+			"*":               {Port: "3307"},
+		},
+	}
+
+	tests := []struct {
+		backend string
+		addr    string
+		typ     string
+		fail    bool
+	}{
+		{"10.0.0.1:3306", "10.0.0.1:9200", "http", false},
+		{"10.0.0.2:3306", "10.0.0.2:9200", "galera", false},
+		{"[fe80::1]:3306", "[fe80::1]:9200", "tcp", false},
+		{"10.0.0.3:3306", "10.0.0.3:3306", "tcp", false},
+		// the default one
+		{"10.0.0.4:3306", "10.0.0.4:3307", "galera", false},
+		// no port to replace
+		{"/tmp/mysql.sock", "", "", true},
+	}
+
+	for _, tt := range tests {
+		addr, typ, err := c.CheckTarget(tt.backend)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s %s", tt.backend, addr, typ)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.backend, err)
+		} else if addr != tt.addr || typ != tt.typ {
+			t.Errorf("%s: expected %s %s, got %s %s", tt.backend, tt.typ, tt.addr, typ, addr)
+		}
+	}
+
+	// the service itself without any check
+	c.Check = nil
+	if addr, typ, err := c.CheckTarget("10.0.0.5:3306"); err != nil || addr != "10.0.0.5:3306" || typ != "galera" {
+		t.Errorf("expected the service check, got %s %s %v", typ, addr, err)
+	}
+}
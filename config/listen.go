@@ -5,14 +5,62 @@
 package config
 
 import (
+	nestor "github.com/zhgwenming/gbalancer/daemon"
 	"net"
+	"strings"
 )
 
 type ListenAddr struct {
 	Net  string
 	Addr string
+	// expects the PROXY protocol header from an upstream balancer
+	Proxy bool
+	// terminates TLS with the TLS settings of the address
+	TLS bool
+	// "primary" or "replica" to forward to the backends of the role only
+	Role string
 }
 
+func (l *ListenAddr) String() string {
+	return l.Net + "://" + l.Addr
+}
+
+// Listen takes over the listener from the previous process on upgrade
 func (l *ListenAddr) Listen() (net.Listener, error) {
-	return net.Listen(l.Net, l.Addr)
+	return nestor.Listen(l.Net, l.Addr)
+}
+
+// Matches tells if a listener handed over by the service manager is the
+// one configured, an empty or wildcard host matches any wildcard address
+func (l *ListenAddr) Matches(addr net.Addr) bool {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		if !strings.HasPrefix(l.Net, "tcp") {
+			return false
+		}
+		host, port, err := net.SplitHostPort(l.Addr)
+		if err != nil {
+			return false
+		}
+		if p, err := net.LookupPort("tcp", port); err != nil || p != a.Port {
+			return false
+		}
+		if host == "" {
+			return a.IP.IsUnspecified()
+		}
+		ips := []net.IP{net.ParseIP(host)}
+		if ips[0] == nil {
+			if ips, err = net.LookupIP(host); err != nil {
+				return false
+			}
+		}
+		for _, ip := range ips {
+			if ip.Equal(a.IP) || ip.IsUnspecified() && a.IP.IsUnspecified() {
+				return true
+			}
+		}
+	case *net.UnixAddr:
+		return strings.HasPrefix(l.Net, "unix") && a.Name == l.Addr
+	}
+	return false
 }
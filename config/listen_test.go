@@ -0,0 +1,47 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package config
+
+import (
+	"net"
+	"testing"
+)
+
+func TestListenAddrMatches(t *testing.T) {
+	tcp := func(ip string, port int) net.Addr {
+		return &net.TCPAddr{IP: net.ParseIP(ip), Port: port}
+	}
+
+	tests := []struct {
+		listen string
+		addr   net.Addr
+		match  bool
+	}{
+		{"tcp://127.0.0.1:3306", tcp("127.0.0.1", 3306), true},
+		{"tcp://127.0.0.1:3306", tcp("127.0.0.1", 3307), false},
+		{"tcp://127.0.0.1:3306", tcp("127.0.0.2", 3306), false},
+		// the wildcards are reported as [::]
+		{"tcp://0.0.0.0:3306?proxy", tcp("::", 3306), true},
+		{"tcp://[::]:3306", tcp("0.0.0.0", 3306), true},
+		{"tcp://:3306", tcp("::", 3306), true},
+		{"tcp://:3306", tcp("127.0.0.1", 3306), false},
+		{"tcp4://0.0.0.0:3306", tcp("0.0.0.0", 3306), true},
+		{"tcp://localhost:3306", tcp("127.0.0.1", 3306), true},
+		{"unix:///tmp/gbalancer.sock", &net.UnixAddr{Name: "/tmp/gbalancer.sock", Net: "unix"}, true},
+		{"unix:///tmp/gbalancer.sock", tcp("::", 3306), false},
+		{"tcp://:3306", &net.UnixAddr{Name: ":3306", Net: "unix"}, false},
+	}
+
+	for _, tt := range tests {
+		c := &Configuration{Listen: []string{tt.listen}}
+		addrs, err := c.GetListenAddrs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if match := addrs[0].Matches(tt.addr); match != tt.match {
+			t.Errorf("%s on %s: expected %v", tt.listen, tt.addr, tt.match)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+)
+
+// TLSConfig is the certificate of a listener, the extra Certs are picked by
+// the server name the clients ask for. The clients need a certificate
+// signed by ClientCA if it's set.
+type TLSConfig struct {
+	Cert     string
+	Key      string
+	Certs    []TLSCert
+	ClientCA string
+}
+
+type TLSCert struct {
+	Cert string
+	Key  string
+}
+
+// BackendTLSConfig wraps the connections to the backends in TLS, the
+// server name defaults to the host of the backend address
+type BackendTLSConfig struct {
+	CA                 string
+	Cert               string
+	Key                string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+func loadPool(file string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+	return pool, nil
+}
+
+// ServerConfig loads the certificates, the first one is the default
+func (t *TLSConfig) ServerConfig() (*tls.Config, error) {
+	certs := append([]TLSCert{{t.Cert, t.Key}}, t.Certs...)
+
+	config := &tls.Config{}
+	for _, c := range certs {
+		cert, err := tls.LoadX509KeyPair(c.Cert, c.Key)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	if t.ClientCA != "" {
+		pool, err := loadPool(t.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// ClientConfig loads the certificates, the server name is left empty if
+// it's to be the host of the backend address
+func (b *BackendTLSConfig) ClientConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         b.ServerName,
+		InsecureSkipVerify: b.InsecureSkipVerify,
+	}
+
+	if b.CA != "" {
+		pool, err := loadPool(b.CA)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	if b.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(b.Cert, b.Key)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// ListenerTLS returns the TLS settings of a listener
+func (c *Configuration) ListenerTLS(addr string) (*tls.Config, error) {
+	t, ok := c.TLS[addr]
+	if !ok {
+		if t, ok = c.TLS["*"]; !ok {
+			return nil, fmt.Errorf("no TLS settings for %s", addr)
+		}
+	}
+
+	config, err := t.ServerConfig()
+	if err != nil {
+		return nil, fmt.Errorf("listener %s: %s", addr, err)
+	}
+	return config, nil
+}
+
+// BackendTLSConfigs loads the TLS settings towards the backends by their
+// addresses, the certificates are read only once here
+func (c *Configuration) BackendTLSConfigs() (map[string]*tls.Config, error) {
+	configs := make(map[string]*tls.Config, len(c.BackendTLS))
+	for addr, b := range c.BackendTLS {
+		config, err := b.ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("backend %s: %s", addr, err)
+		}
+		configs[addr] = config
+	}
+	return configs, nil
+}
+
+// BackendTLS returns the TLS settings towards a backend out of the loaded
+// ones, nil if the connections are plain
+func BackendTLS(configs map[string]*tls.Config, addr string) *tls.Config {
+	config, ok := configs[addr]
+	if !ok {
+		if config, ok = configs["*"]; !ok {
+			return nil
+		}
+	}
+
+	if config.ServerName == "" {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			config = config.Clone()
+			config.ServerName = host
+		}
+	}
+	return config
+}
@@ -0,0 +1,190 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self signed certificate and its key to dir
+func writeCert(t *testing.T, dir, name string) TLSCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := TLSCert{filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")}
+	if err := ioutil.WriteFile(c.Cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(c.Key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := writeCert(t, dir, "db.example.com")
+	other := writeCert(t, dir, "other.example.com")
+	ca := writeCert(t, dir, "ca.example.com")
+	empty := filepath.Join(dir, "empty.pem")
+	if err := ioutil.WriteFile(empty, []byte("no pem here\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.pem")
+
+	c := &Configuration{
+		TLS: map[string]TLSConfig{
+			"tcp://127.0.0.1:3306": {Cert: db.Cert, Key: db.Key, Certs: []TLSCert{other}, ClientCA: ca.Cert},
+			"tcp://127.0.0.1:3307": {Cert: db.Cert, Key: other.Key},
+			"tcp://127.0.0.1:3308": {Cert: db.Cert, Key: db.Key, ClientCA: empty},
+			"tcp://127.0.0.1:3309": {Cert: missing, Key: db.Key},
+			"*":                    {Cert: db.Cert, Key: db.Key},
+		},
+	}
+
+	tests := []struct {
+		listen string
+		certs  int
+		mutual bool
+		fail   bool
+	}{
+		{"tcp://127.0.0.1:3306", 2, true, false},
+		// the default one
+		{"tcp://0.0.0.0:3306", 1, false, false},
+		// key of another certificate
+		{"tcp://127.0.0.1:3307", 0, false, true},
+		{"tcp://127.0.0.1:3308", 0, false, true},
+		{"tcp://127.0.0.1:3309", 0, false, true},
+	}
+
+	for _, tt := range tests {
+		config, err := c.ListenerTLS(tt.listen)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.listen)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.listen, err)
+			continue
+		}
+		if len(config.Certificates) != tt.certs {
+			t.Errorf("%s: expected %d certificates, got %d", tt.listen, tt.certs, len(config.Certificates))
+		}
+		if mutual := config.ClientAuth == tls.RequireAndVerifyClientCert && config.ClientCAs != nil; mutual != tt.mutual {
+			t.Errorf("%s: expected the client verification %v", tt.listen, tt.mutual)
+		}
+	}
+
+	delete(c.TLS, "*")
+	if _, err := c.ListenerTLS("tcp://0.0.0.0:3306"); err == nil {
+		t.Error("expected an error without the TLS settings")
+	}
+}
+
+func TestBackendTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := writeCert(t, dir, "ca.example.com")
+	client := writeCert(t, dir, "client.example.com")
+
+	c := &Configuration{
+		BackendTLS: map[string]BackendTLSConfig{
+			"10.0.0.1:3306": {CA: ca.Cert, Cert: client.Cert, Key: client.Key, ServerName: "db.example.com"},
+			"*":             {InsecureSkipVerify: true},
+		},
+	}
+	configs, err := c.BackendTLSConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		backend    string
+		serverName string
+		ca         bool
+		certs      int
+	}{
+		{"10.0.0.1:3306", "db.example.com", true, 1},
+		// the host of the backend by default
+		{"10.0.0.2:3306", "10.0.0.2", false, 0},
+		{"[fe80::2]:3306", "fe80::2", false, 0},
+		{"/tmp/mysql.sock", "", false, 0},
+	}
+
+	for _, tt := range tests {
+		config := BackendTLS(configs, tt.backend)
+		if config == nil {
+			t.Errorf("%s: expected the TLS settings", tt.backend)
+			continue
+		}
+		if config.ServerName != tt.serverName || (config.RootCAs != nil) != tt.ca || len(config.Certificates) != tt.certs {
+			t.Errorf("%s: unexpected settings %s %v %d", tt.backend, config.ServerName, config.RootCAs != nil, len(config.Certificates))
+		}
+	}
+
+	// the loaded default isn't modified
+	if configs["*"].ServerName != "" {
+		t.Errorf("default server name set to %s", configs["*"].ServerName)
+	}
+
+	delete(configs, "*")
+	if config := BackendTLS(configs, "10.0.0.2:3306"); config != nil {
+		t.Error("expected the plain connections without a default")
+	}
+
+	bad := []BackendTLSConfig{
+		{CA: client.Key},
+		{CA: filepath.Join(dir, "missing.pem")},
+		{Cert: client.Cert, Key: ca.Key},
+	}
+	for i, b := range bad {
+		c.BackendTLS = map[string]BackendTLSConfig{"*": b}
+		if _, err := c.BackendTLSConfigs(); err == nil {
+			t.Errorf("%d: expected an error", i)
+		}
+	}
+}
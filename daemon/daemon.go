@@ -6,9 +6,8 @@ package nestor
 
 import (
 	"fmt"
+	logger "github.com/zhgwenming/gbalancer/log"
 	"github.com/zhgwenming/gbalancer/utils"
-	stdlog "log"
-	"log/syslog"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -25,7 +24,7 @@ const (
 
 var (
 	DefaultDaemon = NewDaemon()
-	log           = NewLogger()
+	log           = logger.New("daemon")
 )
 
 type Handler interface {
@@ -41,6 +40,8 @@ type Daemon struct {
 	WaitSeconds time.Duration
 	Log         logFile
 	h           Handler
+	upgraded    bool
+	hooks       map[os.Signal][]func()
 }
 
 func NewDaemon() *Daemon {
@@ -50,18 +51,8 @@ func NewDaemon() *Daemon {
 	return d
 }
 
-func NewLogger() (l *stdlog.Logger) {
-	// try to use syslog first
-	if logger, err := syslog.NewLogger(syslog.LOG_NOTICE, 0); err != nil {
-		l = stdlog.New(os.Stderr, "", stdlog.LstdFlags)
-	} else {
-		l = logger
-	}
-	return
-}
-
 func fatal(err error) {
-	log.Printf("error: %s\n", err)
+	log.Errorf("error: %s\n", err)
 	os.Exit(1)
 }
 
@@ -70,8 +61,13 @@ func (d *Daemon) setupPidfile() {
 		return
 	}
 
+	// take over the pidfile from the previous process, it's still draining
+	if os.Getenv(ENV_UPGRADE_FD) != "" {
+		os.Remove(d.PidFile)
+	}
+
 	if err := utils.WritePid(d.PidFile); err != nil {
-		log.Printf("error: %s\n", err)
+		log.Errorf("error: %s\n", err)
 		os.Exit(1)
 	}
 }
@@ -205,7 +201,8 @@ func (d *Daemon) Sink() error {
 		syscall.SIGHUP,
 		syscall.SIGINT,
 		syscall.SIGQUIT,
-		syscall.SIGTERM)
+		syscall.SIGTERM,
+		syscall.SIGUSR2)
 
 	if d.PidFile != "" {
 		if _, err := os.Stat(path.Dir(d.PidFile)); os.IsNotExist(err) {
@@ -230,9 +227,11 @@ func (d *Daemon) Sink() error {
 	if p, err := filepath.Abs(os.Args[0]); err != nil {
 		fatal(err)
 	} else {
+		// background processes chdir to /, the absolute path is
+		// needed to re-exec on upgrade
 		d.Command = exec.Cmd{
 			Path: p,
-			Args: os.Args,
+			Args: append([]string{p}, os.Args[1:]...),
 		}
 	}
 
@@ -273,6 +272,29 @@ func (d *Daemon) Serve() {
 func (d *Daemon) WaitSignal() {
 	// waiting for exit signals
 	for sig := range d.Signalc {
+		if hooks, ok := d.hooks[sig]; ok {
+			log.Printf("captured %v\n", sig)
+			for _, hook := range hooks {
+				hook()
+			}
+			continue
+		}
+
+		// forwarded by the supervisor, nothing to do without a hook
+		if sig == syscall.SIGUSR1 || sig == syscall.SIGHUP {
+			log.Debugf("ignored %v without a hook\n", sig)
+			continue
+		}
+
+		if sig == syscall.SIGUSR2 {
+			log.Printf("captured %v, upgrading..\n", sig)
+			if err := d.upgrade(); err != nil {
+				log.Printf("upgrade failed: %s\n", err)
+				continue
+			}
+			break
+		}
+
 		log.Printf("captured %v, exiting..\n", sig)
 		// exit if we get any signal
 		// Todo - catch signal other than SIGTERM/SIGINT
@@ -282,6 +304,19 @@ func (d *Daemon) WaitSignal() {
 	// handler stop routine
 	d.h.Stop()
 
+	if d.upgraded {
+		if drainer, ok := d.h.(Drainer); ok {
+			drainer.Drain()
+		}
+
+		// the pidfile belongs to the new process now, let the
+		// supervisor know we're replaced rather than dead
+		if supervisorControl() != nil {
+			os.Exit(EXIT_UPGRADED)
+		}
+		return
+	}
+
 	d.cleanPidfile()
 	return
 }
@@ -304,6 +339,22 @@ func (d *Daemon) HandleFunc(f func()) {
 	d.h = HandlerFunc(f)
 }
 
+// SetOutput changes the file the output of the background daemon is
+// redirected to, /tmp/<program>.log by default
+func (d *Daemon) SetOutput(path string) {
+	d.Log.path = path
+}
+
+// HandleSignal adds f to run on the signal instead of exiting, the
+// supervisor forwards the signal to the worker
+func (d *Daemon) HandleSignal(sig os.Signal, f func()) {
+	if d.hooks == nil {
+		d.hooks = make(map[os.Signal][]func())
+	}
+	d.hooks[sig] = append(d.hooks[sig], f)
+	signal.Notify(d.Signalc, sig)
+}
+
 type SinkServer interface {
 	Sink() error
 	Serve()
@@ -332,6 +383,9 @@ func Start(s SinkServer) error {
 	// handler serve
 	s.Serve()
 
+	// the previous process stops accepting once we're serving
+	notifyReady()
+
 	// wait to exit
 	s.WaitSignal()
 	return nil
@@ -0,0 +1,50 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package nestor
+
+import (
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type stopHandler chan struct{}
+
+func (h stopHandler) Serve() {}
+
+func (h stopHandler) Stop() {
+	close(h)
+}
+
+func TestWaitSignalHangup(t *testing.T) {
+	d := NewDaemon()
+	stopped := make(stopHandler)
+	d.Handle(stopped)
+
+	signal.Notify(d.Signalc, syscall.SIGHUP, syscall.SIGTERM)
+	defer signal.Reset(syscall.SIGHUP, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		d.WaitSignal()
+		close(done)
+	}()
+
+	// the supervisor forwards SIGHUP, the worker has no hook for it
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	select {
+	case <-stopped:
+		t.Fatal("the worker stopped on SIGHUP")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the worker didn't stop on SIGTERM")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package nestor
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	// fd=net://addr pairs of the listeners inherited from the previous process
+	ENV_LISTENERS = "__GO_LISTENERS"
+)
+
+type listenerEntry struct {
+	key       string
+	listener  net.Listener
+	activated bool
+}
+
+var (
+	listenMutex sync.Mutex
+	inherited   map[string]*os.File
+	listeners   []listenerEntry
+)
+
+func listenKey(network, addr string) string {
+	return network + "://" + addr
+}
+
+// loadInherited parses the listeners passed by the previous process, it
+// needs to be called with the listenMutex held
+func loadInherited() {
+	if inherited != nil {
+		return
+	}
+
+	inherited = make(map[string]*os.File)
+	env := os.Getenv(ENV_LISTENERS)
+	if env == "" {
+		return
+	}
+	os.Setenv(ENV_LISTENERS, "")
+
+	for _, pair := range strings.Split(env, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			log.Printf("bad inherited listener %s", pair)
+			continue
+		}
+
+		fd, err := strconv.Atoi(parts[0])
+		if err != nil {
+			log.Printf("bad inherited listener %s", pair)
+			continue
+		}
+		inherited[parts[1]] = os.NewFile(uintptr(fd), parts[1])
+	}
+}
+
+// Listen returns the listener inherited from the previous process if there's
+// one for the address, otherwise a new one is created. The listeners are
+// recorded to be handed over to the next process on upgrade.
+func Listen(network, addr string) (net.Listener, error) {
+	listenMutex.Lock()
+	defer listenMutex.Unlock()
+
+	loadInherited()
+
+	key := listenKey(network, addr)
+
+	var l net.Listener
+	var err error
+	if file, ok := inherited[key]; ok {
+		delete(inherited, key)
+		l, err = net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inherited listener %s: %s", key, err)
+		}
+
+		// remove the unix socket on stop as a listener created by ourself
+		if unix, ok := l.(*net.UnixListener); ok {
+			unix.SetUnlinkOnClose(true)
+		}
+		log.Printf("inherited listener %s", key)
+	} else {
+		if l, err = net.Listen(network, addr); err != nil {
+			return nil, err
+		}
+	}
+
+	listeners = append(listeners, listenerEntry{key, l, false})
+	return l, nil
+}
+
+// socketActivated returns true if our listeners came from systemd
+func socketActivated() bool {
+	listenMutex.Lock()
+	defer listenMutex.Unlock()
+
+	for _, entry := range listeners {
+		if entry.activated {
+			return true
+		}
+	}
+	return false
+}
+
+// listenerFds duplicates the listeners to be passed to a new process, the
+// raw fds are used since os.File.Fd() would switch the shared listening
+// socket to blocking mode, which makes Accept unable to be interrupted
+func listenerFds() ([]string, []int, error) {
+	listenMutex.Lock()
+	defer listenMutex.Unlock()
+
+	keys := make([]string, 0, len(listeners))
+	fds := make([]int, 0, len(listeners))
+	for _, entry := range listeners {
+		sc, ok := entry.listener.(syscall.Conn)
+		if !ok {
+			closeFds(fds)
+			return nil, nil, fmt.Errorf("listener %s can't be handed over", entry.key)
+		}
+
+		raw, err := sc.SyscallConn()
+		if err != nil {
+			closeFds(fds)
+			return nil, nil, err
+		}
+
+		var fd int
+		var dupErr error
+		err = raw.Control(func(s uintptr) {
+			fd, dupErr = syscall.Dup(int(s))
+		})
+		if err == nil {
+			err = dupErr
+		}
+		if err != nil {
+			closeFds(fds)
+			return nil, nil, err
+		}
+
+		syscall.CloseOnExec(fd)
+		keys = append(keys, entry.key)
+		fds = append(fds, fd)
+	}
+	return keys, fds, nil
+}
+
+// handoverListeners keeps the unix sockets in place on stop, they're
+// now served by the new process
+func handoverListeners() {
+	listenMutex.Lock()
+	defer listenMutex.Unlock()
+
+	for _, entry := range listeners {
+		if unix, ok := entry.listener.(*net.UnixListener); ok {
+			unix.SetUnlinkOnClose(false)
+		}
+	}
+}
+
+func closeFds(fds []int) {
+	for _, fd := range fds {
+		syscall.Close(fd)
+	}
+}
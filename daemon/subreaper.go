@@ -0,0 +1,16 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+//go:build !linux
+// +build !linux
+
+package nestor
+
+import (
+	"fmt"
+)
+
+func setSubreaper() error {
+	return fmt.Errorf("subreaper is not supported")
+}
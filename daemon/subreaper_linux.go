@@ -0,0 +1,25 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+//go:build linux
+// +build linux
+
+package nestor
+
+import (
+	"syscall"
+)
+
+const (
+	PR_SET_CHILD_SUBREAPER = 36
+)
+
+// setSubreaper makes the orphaned descendants to be reparented to us
+func setSubreaper() error {
+	_, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, PR_SET_CHILD_SUBREAPER, 1, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -5,9 +5,12 @@
 package nestor
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -22,66 +25,249 @@ var (
 
 type Supervisor struct {
 	Daemon
+	// restart delay, doubled on every crash up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// a worker running longer than StableTime resets the backoff
+	StableTime time.Duration
+	// give up after so many crashes in a row, 0 means never
+	CrashLimit int
+	// exit codes of the worker which mean restarting won't help
+	NoRestart []int
+	// how long to wait for the worker to exit on SIGTERM
+	StopTimeout time.Duration
+	worker      int
+	control     *os.File
+	successor   chan int
 }
 
 func NewSupervisor() *Supervisor {
 	d := NewDaemon()
-	return &Supervisor{*d}
+	return &Supervisor{
+		Daemon:      *d,
+		MinBackoff:  time.Second,
+		MaxBackoff:  time.Minute,
+		StableTime:  time.Minute,
+		CrashLimit:  10,
+		StopTimeout: 30 * time.Second,
+	}
+}
+
+// readControl reads the pid of the new worker reported on upgrade
+func (s *Supervisor) readControl(r *os.File) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		pid, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+		if err != nil {
+			log.Printf("bad worker pid %q\n", scanner.Text())
+			continue
+		}
+		s.successor <- pid
+	}
+}
+
+// reap collects all the exited children, returns true along with the exit
+// status if the current worker is gone. The old worker of an upgrade is
+// replaced by its successor, which got reparented to us.
+func (s *Supervisor) reap() (bool, syscall.WaitStatus) {
+	for {
+		var status syscall.WaitStatus
+		pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+		if err != nil || pid <= 0 {
+			return false, status
+		}
+
+		if pid != s.worker {
+			log.Printf("previous worker[%d] exited\n", pid)
+			continue
+		}
+
+		if status.Exited() && status.ExitStatus() == EXIT_UPGRADED {
+			select {
+			case s.worker = <-s.successor:
+				log.Printf("worker[%d] upgraded to pid %d\n", pid, s.worker)
+				continue
+			case <-time.After(time.Second):
+				log.Printf("worker[%d] upgraded without a successor\n", pid)
+			}
+		} else if status.Signaled() {
+			log.Printf("worker[%d] exited with - signal: %v\n", pid, status.Signal())
+		} else {
+			log.Printf("worker[%d] exited with - exit status %d\n", pid, status.ExitStatus())
+		}
+		return true, status
+	}
 }
 
-func (s *Supervisor) startWorker() {
+// handleSignal runs the hook of the signal and forwards it to the worker,
+// it stops the worker and exits on SIGTERM
+func (s *Supervisor) handleSignal(sig os.Signal) {
+	for _, hook := range s.hooks[sig] {
+		hook()
+	}
+
+	switch sig {
+	case syscall.SIGTERM:
+		s.stop()
+	case syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2:
+		if s.worker != 0 {
+			syscall.Kill(s.worker, sig.(syscall.Signal))
+		}
+	}
+}
+
+// stop terminates the worker and exits, the worker is killed if it
+// didn't exit in StopTimeout
+func (s *Supervisor) stop() {
+	if s.worker != 0 {
+		syscall.Kill(s.worker, syscall.SIGTERM)
+
+		timeout := time.After(s.StopTimeout)
+	wait:
+		for {
+			select {
+			case sig := <-s.Signalc:
+				if sig != syscall.SIGCHLD {
+					continue
+				}
+				if exited, _ := s.reap(); exited {
+					break wait
+				}
+			case pid := <-s.successor:
+				s.worker = pid
+			case <-timeout:
+				log.Printf("worker[%d] didn't exit in %v, killing it\n", s.worker, s.StopTimeout)
+				syscall.Kill(s.worker, syscall.SIGKILL)
+				timeout = nil
+			}
+		}
+	}
+
+	s.cleanPidfile()
+	os.Exit(0)
+}
+
+// startWorker starts a worker and waits for it to exit
+func (s *Supervisor) startWorker() syscall.WaitStatus {
 	cmd := s.Command
 
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
+	// the worker reports its successor on upgrade through fd 3
+	cmd.ExtraFiles = []*os.File{s.control}
+	cmd.Env = append(childEnv(), ENV_SUPERVISOR_FD+"=3")
+
 	if err := cmd.Start(); err == nil {
 		log.Printf("- Started worker as pid %d\n", cmd.Process.Pid)
 	} else {
-		log.Printf("error to start worker - %s\n", err)
+		log.Errorf("error to start worker - %s\n", err)
 		os.Exit(1)
 	}
+	s.worker = cmd.Process.Pid
 
-	for sig := range s.Signalc {
-		log.Printf("monitor captured %v\n", sig)
-		if sig == syscall.SIGCHLD {
-			break
+	for {
+		select {
+		case sig := <-s.Signalc:
+			log.Printf("monitor captured %v\n", sig)
+			if sig != syscall.SIGCHLD {
+				s.handleSignal(sig)
+				continue
+			}
+
+			if exited, status := s.reap(); exited {
+				s.worker = 0
+				return status
+			}
+		case pid := <-s.successor:
+			// the old worker is still draining
+			log.Printf("worker[%d] upgraded to pid %d\n", s.worker, pid)
+			s.worker = pid
 		}
+	}
+}
 
-		// only exit if we got a TERM signal
-		if sig == syscall.SIGTERM {
-			cmd.Process.Signal(sig)
-			os.Exit(0)
+// wait sleeps for the backoff while still handling the signals
+func (s *Supervisor) wait(d time.Duration) {
+	timeout := time.After(d)
+	for {
+		select {
+		case sig := <-s.Signalc:
+			if sig != syscall.SIGCHLD {
+				log.Printf("monitor captured %v\n", sig)
+				s.handleSignal(sig)
+			}
+		case <-timeout:
+			return
 		}
 	}
+}
 
-	if err := cmd.Wait(); err != nil {
-		log.Printf("worker[%d] exited with - %s\n", cmd.Process.Pid, err)
+func (s *Supervisor) noRestart(status syscall.WaitStatus) bool {
+	if !status.Exited() {
+		return false
 	}
 
+	for _, code := range s.NoRestart {
+		if status.ExitStatus() == code {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *Supervisor) supervise() {
 	signal.Notify(s.Signalc,
-		syscall.SIGCHLD)
+		syscall.SIGCHLD,
+		syscall.SIGUSR1)
+
+	// adopt the new worker started by the old one on upgrade
+	if err := setSubreaper(); err != nil {
+		log.Printf("failed to become subreaper - %s\n", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		fatal(err)
+	}
+	s.control = w
+	s.successor = make(chan int, 1)
+	go s.readControl(r)
 
 	// process manager
+	backoff := s.MinBackoff
+	crashes := 0
 	for {
 		startTime := time.Now()
-		s.startWorker()
-		for {
-			endTime := time.Now()
-			duration := endTime.Sub(startTime)
-			seconds := duration.Seconds()
-
-			// restart for every 5s
-			if seconds > 5 {
-				break
-			} else {
-				time.Sleep(time.Second)
-			}
+		status := s.startWorker()
+
+		if s.noRestart(status) {
+			log.Printf("worker exited with %d, not restarting\n", status.ExitStatus())
+			s.cleanPidfile()
+			os.Exit(status.ExitStatus())
+		}
+
+		// a worker exited cleanly or ran long enough isn't in a crash loop
+		if time.Since(startTime) >= s.StableTime || (status.Exited() && status.ExitStatus() == 0) {
+			backoff = s.MinBackoff
+			crashes = 0
+		} else {
+			crashes++
+		}
+
+		if s.CrashLimit > 0 && crashes >= s.CrashLimit {
+			log.Errorf("worker crashed %d times in a row, giving up\n", crashes)
+			s.cleanPidfile()
+			os.Exit(1)
+		}
+
+		log.Printf("restarting worker in %v\n", backoff)
+		s.wait(backoff)
+
+		backoff *= 2
+		if backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
 		}
-		log.Printf("restarting worker\n")
 	}
 }
 
@@ -111,6 +297,16 @@ func (s *Supervisor) Sink() error {
 			fatal(err)
 		}
 
+		// the pidfile belongs to the supervisor
+		s.PidFile = ""
+
+		// exit gracefully on the signals forwarded by the supervisor
+		signal.Notify(s.Signalc,
+			syscall.SIGHUP,
+			syscall.SIGTERM,
+			syscall.SIGUSR1,
+			syscall.SIGUSR2)
+
 	default:
 		err := fmt.Errorf("critical error, unknown mode: %s", mode)
 		fmt.Println(err)
@@ -132,3 +328,7 @@ func HandleFunc(pidfile string, foreground bool, f func()) SinkServer {
 	h := HandlerFunc(f)
 	return Handle(pidfile, foreground, h)
 }
+
+func HandleSignal(sig os.Signal, f func()) {
+	DefaultSupervisor.HandleSignal(sig, f)
+}
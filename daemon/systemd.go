@@ -0,0 +1,121 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package nestor
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	SD_LISTEN_FDS_START = 3
+	// the inherited listeners came from systemd socket activation
+	ENV_ACTIVATED = "__GO_ACTIVATED"
+)
+
+// Notify sends the state to systemd, it's a no-op if we're not started
+// as a Type=notify service
+func Notify(state string) error {
+	name := os.Getenv("NOTIFY_SOCKET")
+	if name == "" {
+		return nil
+	}
+
+	// abstract socket
+	if name[0] == '@' {
+		name = "\x00" + name[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// WatchdogInterval returns how often the watchdog should be pinged,
+// 0 if the watchdog isn't enabled for us
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	// ping twice in a period as recommended by sd_watchdog_enabled(3)
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// Activated returns the listeners passed by systemd socket activation, the
+// listeners a socket activated process handed over on upgrade are
+// returned as well. It returns nil if we're not socket activated.
+func Activated() ([]net.Listener, error) {
+	listenMutex.Lock()
+	defer listenMutex.Unlock()
+
+	files := make([]*os.File, 0)
+	if os.Getenv(ENV_ACTIVATED) != "" {
+		os.Setenv(ENV_ACTIVATED, "")
+		loadInherited()
+
+		keys := make([]string, 0, len(inherited))
+		for key := range inherited {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			files = append(files, inherited[key])
+			delete(inherited, key)
+		}
+	} else if os.Getenv("LISTEN_PID") == strconv.Itoa(os.Getpid()) {
+		n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+		if err != nil {
+			return nil, fmt.Errorf("bad LISTEN_FDS: %s", err)
+		}
+
+		// not to be inherited by our children
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+
+		for fd := SD_LISTEN_FDS_START; fd < SD_LISTEN_FDS_START+n; fd++ {
+			syscall.CloseOnExec(fd)
+			files = append(files, os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd)))
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	result := make([]net.Listener, 0, len(files))
+	for _, file := range files {
+		l, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("activated listener %s: %s", file.Name(), err)
+		}
+
+		addr := l.Addr()
+		key := listenKey(addr.Network(), addr.String())
+		log.Printf("activated listener %s", key)
+
+		listeners = append(listeners, listenerEntry{key, l, true})
+		result = append(result, l)
+	}
+	return result, nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package nestor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// upgrade flow on SIGUSR2:
+//
+//  1. the old process starts the new binary with the listeners and a
+//     readiness pipe passed as extra files
+//  2. the new process takes over the listeners and reports readiness once served
+//  3. the old process stops accepting, drains the in-flight work and exits
+//
+// a supervised worker also reports the pid of the new worker to the
+// supervisor, which adopts it as a child subreaper
+const (
+	ENV_UPGRADE_FD    = "__GO_UPGRADE_FD"
+	ENV_SUPERVISOR_FD = "__GO_SUPERVISOR_FD"
+	EXIT_UPGRADED     = 3
+	UpgradeTimeout    = 30 * time.Second
+)
+
+// Drainer is implemented by the handlers which can wait for the in-flight
+// work to finish after Stop, it's called before exiting on upgrade
+type Drainer interface {
+	Drain()
+}
+
+var (
+	controlOnce sync.Once
+	control     *os.File
+)
+
+func envFile(env string) *os.File {
+	value := os.Getenv(env)
+	if value == "" {
+		return nil
+	}
+
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("bad %s: %s", env, value)
+		return nil
+	}
+	return os.NewFile(uintptr(fd), env)
+}
+
+// supervisorControl returns the pipe to the supervisor if we're a supervised worker
+func supervisorControl() *os.File {
+	controlOnce.Do(func() {
+		control = envFile(ENV_SUPERVISOR_FD)
+	})
+	return control
+}
+
+// childEnv returns our environment without the upgrade related settings
+func childEnv() []string {
+	env := make([]string, 0)
+	for _, e := range os.Environ() {
+		name := strings.SplitN(e, "=", 2)[0]
+		switch name {
+		case ENV_LISTENERS, ENV_UPGRADE_FD, ENV_SUPERVISOR_FD, ENV_ACTIVATED:
+			continue
+		// systemd settings bound to our pid
+		case "WATCHDOG_PID", "LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES":
+			continue
+		}
+		env = append(env, e)
+	}
+	return env
+}
+
+// notifyReady tells the previous process we're serving, it's a no-op if
+// we're not started by an upgrade
+func notifyReady() {
+	ready := envFile(ENV_UPGRADE_FD)
+	if ready == nil {
+		return
+	}
+
+	// we're the main process of the service now
+	if err := Notify(fmt.Sprintf("MAINPID=%d", os.Getpid())); err != nil {
+		log.Printf("sd_notify: %s", err)
+	}
+
+	ready.Write([]byte("1"))
+	ready.Close()
+}
+
+// upgradeEnv returns the environment and the files of the new process, the
+// readiness pipe is passed as fd 3 and the listeners as the fds following it
+func upgradeEnv(ready *os.File, keys []string, fds []int) ([]string, []uintptr) {
+	files := []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd(), ready.Fd()}
+	env := childEnv()
+	env = append(env, ENV_UPGRADE_FD+"=3")
+	if c := supervisorControl(); c != nil {
+		env = append(env, ENV_SUPERVISOR_FD+"="+strconv.Itoa(len(files)))
+		files = append(files, c.Fd())
+	}
+
+	pairs := make([]string, 0, len(keys))
+	for i, key := range keys {
+		pairs = append(pairs, strconv.Itoa(len(files))+"="+key)
+		files = append(files, uintptr(fds[i]))
+	}
+	env = append(env, ENV_LISTENERS+"="+strings.Join(pairs, ","))
+	if socketActivated() {
+		env = append(env, ENV_ACTIVATED+"=1")
+	}
+	return env, files
+}
+
+// upgrade starts the new binary with our listeners, it returns once the new
+// process is ready, the caller should stop accepting afterwards
+func (d *Daemon) upgrade() error {
+	// background processes are started with the absolute path, and
+	// foreground process never changes its working directory
+	path, err := filepath.Abs(os.Args[0])
+	if err != nil {
+		return err
+	}
+
+	keys, fds, err := listenerFds()
+	if err != nil {
+		return err
+	}
+	defer closeFds(fds)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	env, files := upgradeEnv(w, keys, fds)
+	pid, err := syscall.ForkExec(path, os.Args, &syscall.ProcAttr{Env: env, Files: files})
+	w.Close()
+	if err != nil {
+		return err
+	}
+	log.Printf("- Started new process as pid %d\n", pid)
+	process, _ := os.FindProcess(pid)
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := r.Read(buf)
+		ready <- err
+	}()
+
+	select {
+	case err = <-ready:
+		if err != nil {
+			err = fmt.Errorf("new process exited before ready")
+		}
+	case <-time.After(UpgradeTimeout):
+		err = fmt.Errorf("new process not ready in %v", UpgradeTimeout)
+	}
+
+	if err != nil {
+		process.Kill()
+		process.Wait()
+		return err
+	}
+
+	handoverListeners()
+
+	// let the supervisor to adopt the new worker
+	if c := supervisorControl(); c != nil {
+		fmt.Fprintf(c, "%d\n", pid)
+	}
+
+	d.upgraded = true
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package nestor
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const envUpgradeTest = "__GO_UPGRADE_TEST"
+
+// TestUpgradeChild is the new process started by TestUpgradeHandoff, it
+// serves a client on the inherited listener
+func TestUpgradeChild(t *testing.T) {
+	addr := os.Getenv(envUpgradeTest)
+	if addr == "" {
+		t.Skip("started by TestUpgradeHandoff only")
+	}
+
+	listenMutex.Lock()
+	loadInherited()
+	_, ok := inherited[listenKey("tcp", addr)]
+	listenMutex.Unlock()
+	if !ok {
+		t.Fatalf("listener %s not inherited", addr)
+	}
+
+	l, err := Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	notifyReady()
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("new process\n"))
+	conn.Close()
+}
+
+func TestUpgradeHandoff(t *testing.T) {
+	l, err := Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		listenMutex.Lock()
+		listeners = nil
+		listenMutex.Unlock()
+		l.Close()
+	}()
+
+	keys, fds, err := listenerFds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFds(fds)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// the key of the listener is the address it was asked for
+	env, files := upgradeEnv(w, keys, fds)
+	env = append(env, envUpgradeTest+"=127.0.0.1:0")
+	args := []string{os.Args[0], "-test.run=^TestUpgradeChild$"}
+	pid, err := syscall.ForkExec(os.Args[0], args, &syscall.ProcAttr{Env: env, Files: files})
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	process, _ := os.FindProcess(pid)
+	defer process.Wait()
+
+	ready := make(chan error, 1)
+	go func() {
+		_, err := r.Read(make([]byte, 1))
+		ready <- err
+	}()
+	select {
+	case err := <-ready:
+		if err != nil {
+			t.Fatal("new process exited before ready")
+		}
+	case <-time.After(10 * time.Second):
+		process.Kill()
+		t.Fatal("new process not ready")
+	}
+
+	// we don't accept, the client can only be served by the new process
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "new process\n" {
+		t.Errorf("unexpected reply %q %v", line, err)
+	}
+}
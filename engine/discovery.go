@@ -0,0 +1,124 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package engine
+
+import (
+	"github.com/zhgwenming/gbalancer/cluster"
+	"github.com/zhgwenming/gbalancer/config"
+	"github.com/zhgwenming/gbalancer/wrangler"
+	"time"
+)
+
+const (
+	ServiceName     = "gbalancer"
+	PublishInterval = 10
+)
+
+// service settings and backends sourced from the coordinator, the layout:
+//
+//	gbalancer/{cluster}/config          - json with the same keys as the config file
+//	gbalancer/{cluster}/resource/{addr} - one backend address per key
+type discovery struct {
+	client *cluster.Client
+	local  config.Configuration
+}
+
+func newDiscovery(settings *config.Configuration) (*discovery, error) {
+	endpoints := settings.CoordinatorEndpoints()
+	client, err := cluster.NewClient(ServiceName, settings.Cluster, endpoints, 3*PublishInterval)
+	if err != nil {
+		return nil, err
+	}
+	return &discovery{client, *settings}, nil
+}
+
+// load returns the local settings merged with the shared ones
+func (d *discovery) load() *config.Configuration {
+	settings := d.local
+	backends := make([]string, 0, wrangler.MaxBackends)
+	seen := make(map[string]bool, wrangler.MaxBackends)
+
+	add := func(addrs []string) {
+		for _, addr := range addrs {
+			if !seen[addr] {
+				seen[addr] = true
+				backends = append(backends, addr)
+			}
+		}
+	}
+
+	add(d.local.Backend)
+
+	if data, err := d.client.Config(); err != nil {
+		log.Warnf("discovery: no shared config - %s", err)
+	} else if shared, err := config.LoadSharedConfig(data); err != nil {
+		log.Warnf("discovery: bad shared config - %s", err)
+	} else {
+		settings.Merge(shared)
+		add(shared.Backend)
+	}
+
+	if resources, err := d.client.Resources(); err != nil {
+		log.Warnf("discovery: no resources - %s", err)
+	} else {
+		add(resources)
+	}
+
+	settings.Backend = backends
+	return &settings
+}
+
+// watch feeds the wrangler with the new settings on every change
+func (d *discovery) watch(wgl *wrangler.Wrangler) {
+	changed := make(chan struct{})
+
+	go func() {
+		for {
+			err := d.client.WatchConfig(changed, nil)
+			log.Warnf("discovery: watch error - %s, retrying", err)
+			time.Sleep(5 * time.Second)
+
+			// changes might be missed while we're not watching
+			changed <- struct{}{}
+		}
+	}()
+
+	for _ = range changed {
+		wgl.UpdateConfig(d.load())
+	}
+}
+
+// publish reports our view of the backends periodically, active and
+// ejected are nil if the engine doesn't track the connections
+func (d *discovery) publish(wgl *wrangler.Wrangler, active func() map[string]uint, ejected func() map[string]time.Time) {
+	ticker := time.NewTicker(PublishInterval * time.Second)
+	for {
+		var conns map[string]uint
+		var ejections map[string]time.Time
+		if active != nil {
+			conns = active()
+		}
+		if ejected != nil {
+			ejections = ejected()
+		}
+
+		backends := make(map[string]*cluster.BackendHealth, wrangler.MaxBackends)
+		for addr, err := range wgl.Status() {
+			b := &cluster.BackendHealth{Up: err == nil, Active: conns[addr]}
+			if err != nil {
+				b.Error = err.Error()
+			}
+			if until, ok := ejections[addr]; ok {
+				b.Ejected = &until
+			}
+			backends[addr] = b
+		}
+
+		if err := d.client.PublishHealth(backends); err != nil {
+			log.Warnf("discovery: failed to publish health - %s", err)
+		}
+		<-ticker.C
+	}
+}
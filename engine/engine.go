@@ -7,28 +7,45 @@ package engine
 import (
 	"flag"
 	"github.com/zhgwenming/gbalancer/config"
+	nestor "github.com/zhgwenming/gbalancer/daemon"
 	"github.com/zhgwenming/gbalancer/engine/ipvs"
 	"github.com/zhgwenming/gbalancer/engine/native"
 	logger "github.com/zhgwenming/gbalancer/log"
 	"github.com/zhgwenming/gbalancer/wrangler"
 	"sync"
+	"time"
 )
 
 var (
-	log        = logger.NewLogger()
+	log        = logger.New("engine")
 	ipvsMode   = flag.Bool("ipvs", false, "to use lvs as loadbalancer")
 	ipvsRemote = flag.Bool("remote", false, "independent director")
 )
 
-func Serve(settings *config.Configuration, wgroup *sync.WaitGroup) (done chan struct{}) {
+// Serve starts the service, active returns the ongoing connections of
+// the backends, it's nil if the engine doesn't track them. The error is
+// returned if the settings are invalid.
+func Serve(settings *config.Configuration, wgroup *sync.WaitGroup) (done chan struct{}, active func() map[string]uint, err error) {
 	status := make(chan map[string]int, native.MaxBackends)
 	//status := make(chan *BEStatus)
 
-	// start the wrangler
-	wgl := wrangler.NewWrangler(settings, status)
+	// source the service settings and backends from the coordinator if configured
+	var disc *discovery
+	if len(settings.CoordinatorEndpoints()) > 0 {
+		if disc, err = newDiscovery(settings); err != nil {
+			return nil, nil, err
+		}
+		settings = disc.load()
+	}
 
-	go wgl.Monitor()
+	// start the wrangler
+	wgl, err := wrangler.NewWrangler(settings, status)
+	if err != nil {
+		return nil, nil, err
+	}
+	wgl.Notify = nestor.Notify
 
+	var ejected func() map[string]time.Time
 	done = make(chan struct{})
 	if *ipvsMode {
 		wgroup.Add(1)
@@ -41,7 +58,22 @@ func Serve(settings *config.Configuration, wgroup *sync.WaitGroup) (done chan st
 			go ipvs.LocalSchedule(status)
 		}
 	} else {
-		native.Serve(settings, wgroup, done, status)
+		sch, err := native.Serve(settings, wgroup, done, status)
+		if err != nil {
+			return nil, nil, err
+		}
+		active = sch.ActiveConnections
+		ejected = sch.Ejections
+	}
+
+	go wgl.Monitor()
+
+	if disc != nil {
+		go disc.watch(wgl)
+	}
+
+	if disc != nil && settings.Publish {
+		go disc.publish(wgl, active, ejected)
 	}
-	return done
+	return done, active, nil
 }
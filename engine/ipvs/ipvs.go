@@ -6,6 +6,7 @@ package ipvs
 
 import (
 	"fmt"
+	nestor "github.com/zhgwenming/gbalancer/daemon"
 	logger "github.com/zhgwenming/gbalancer/log"
 	"github.com/zhgwenming/gbalancer/utils"
 	"os"
@@ -13,12 +14,18 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	IPvsLocalAddr = "127.1.1.1"
 )
 
+// the wrangler takes a backend out with it
+const (
+	FlagDown int = 0
+)
+
 type IPvs struct {
 	Addr      string
 	Port      string
@@ -30,7 +37,7 @@ type IPvs struct {
 }
 
 var (
-	log = logger.NewLogger()
+	log = logger.New("ipvs")
 )
 
 func NewIPvs(addr, port, sch string, done <-chan struct{}, wgroup *sync.WaitGroup) *IPvs {
@@ -79,24 +86,30 @@ func ensureCommands(cmds []string) error {
 //
 
 func (i *IPvs) eventLoop(status <-chan map[string]int) {
+	var watchdog <-chan time.Time
+	if interval := nestor.WatchdogInterval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		watchdog = ticker.C
+	}
+
 	for {
 		select {
 		case backends := <-status:
-			if len(backends) == 0 {
-				log.Printf("balancer: got empty backends list")
-			}
-
-			for addr, _ := range i.backends {
-				if _, ok := backends[addr]; !ok {
-					i.RemoveBackend(addr)
-				} else {
-					delete(backends, addr)
+			// only the changed backends come, FlagDown for the gone ones
+			for addr, flag := range backends {
+				_, ok := i.backends[addr]
+				if flag == FlagDown {
+					if ok {
+						i.RemoveBackend(addr)
+					}
+				} else if !ok {
+					i.AddBackend(addr)
 				}
 			}
-
-			// the rest of active backends, add them
-			for addr, _ := range backends {
-				i.AddBackend(addr)
+		case <-watchdog:
+			if err := nestor.Notify("WATCHDOG=1"); err != nil {
+				log.Warnf("sd_notify: %s\n", err)
 			}
 		case <-i.done:
 			return
@@ -219,6 +232,6 @@ func (i *IPvs) RemoveBackend(addr string) {
 		}
 		delete(i.backends, addr)
 	} else {
-		log.Printf("balancer: %s is not up, bug might exist!", addr)
+		log.Errorf("balancer: %s is not up, bug might exist!", addr)
 	}
 }
@@ -0,0 +1,45 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package ipvs
+
+const (
+	GratuitousArpCount = "3"
+)
+
+// virtual ip owned by the director leader
+type VirtualIP struct {
+	Addr  string
+	Iface string
+}
+
+func NewVirtualIP(addr, iface string) *VirtualIP {
+	return &VirtualIP{addr, iface}
+}
+
+// % ip addr add 192.168.100.30/32 dev eth0
+// % arping -U -c 3 -I eth0 192.168.100.30
+func (v *VirtualIP) Up() error {
+	cmd := "ip addr add " + v.Addr + "/32 dev " + v.Iface
+	if err := runCommand(cmd); err != nil {
+		return err
+	}
+	log.Printf("vip: added %s to %s", v.Addr, v.Iface)
+
+	// announce the new owner of the vip to the neighbours,
+	// failure is not fatal since the arp cache will expire anyway
+	cmd = "arping -U -c " + GratuitousArpCount + " -I " + v.Iface + " " + v.Addr
+	runCommand(cmd)
+
+	return nil
+}
+
+func (v *VirtualIP) Down() error {
+	cmd := "ip addr del " + v.Addr + "/32 dev " + v.Iface
+	if err := runCommand(cmd); err != nil {
+		return err
+	}
+	log.Printf("vip: removed %s from %s", v.Addr, v.Iface)
+	return nil
+}
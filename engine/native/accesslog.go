@@ -0,0 +1,173 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package native
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// entries buffered for the writer, the newer ones are dropped if it's full
+	AccessLogBuffer = 4096
+)
+
+type accessEntry struct {
+	Time     time.Time     `json:"time"`
+	Client   string        `json:"client"`
+	Listener string        `json:"listener"`
+	Backend  string        `json:"backend"`
+	Mode     string        `json:"mode"`
+	Dial     time.Duration `json:"dial_us"`
+	Duration time.Duration `json:"duration_us"`
+	BytesIn  int64         `json:"bytes_in"`
+	BytesOut int64         `json:"bytes_out"`
+	Retries  int           `json:"retries"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func newAccessEntry(req *Request, err error) *accessEntry {
+	now := time.Now()
+	entry := &accessEntry{
+		Time:     now,
+		Listener: req.listener,
+		Backend:  "-",
+		Mode:     "direct",
+		Dial:     req.dialTime,
+		Duration: now.Sub(req.start),
+		BytesIn:  req.bytesIn,
+		BytesOut: req.bytesOut,
+		Retries:  req.retries,
+	}
+
+	// unix clients are mostly unnamed
+	if addr := req.Conn.RemoteAddr(); addr != nil && addr.String() != "" && addr.String() != "@" {
+		entry.Client = addr.String()
+	} else {
+		entry.Client = "-"
+	}
+
+	if req.backend != nil {
+		entry.Backend = req.backend.address
+	}
+	if req.tunnel {
+		entry.Mode = "tunnel"
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+func (e *accessEntry) text() string {
+	line := fmt.Sprintf("%s client=%s listener=%s backend=%s mode=%s dial=%v duration=%v bytes_in=%d bytes_out=%d retries=%d",
+		e.Time.Format("2006/01/02 15:04:05"), e.Client, e.Listener, e.Backend, e.Mode,
+		e.Dial, e.Duration, e.BytesIn, e.BytesOut, e.Retries)
+
+	if e.Error != "" {
+		line += " error=" + strconv.Quote(e.Error)
+	}
+	return line
+}
+
+func (e *accessEntry) json() string {
+	// durations in microseconds
+	entry := *e
+	entry.Dial /= time.Microsecond
+	entry.Duration /= time.Microsecond
+
+	data, _ := json.Marshal(&entry)
+	return string(data)
+}
+
+// accessLog writes an entry per connection in its own goroutine, the
+// scheduler never waits for the disk
+type accessLog struct {
+	path     string
+	asJSON   bool
+	entries  chan *accessEntry
+	reopenc  chan struct{}
+	dropped  uint64
+	file     *os.File
+	buffered *bufio.Writer
+}
+
+func newAccessLog(path string, asJSON bool) (*accessLog, error) {
+	a := &accessLog{
+		path:    path,
+		asJSON:  asJSON,
+		entries: make(chan *accessEntry, AccessLogBuffer),
+		reopenc: make(chan struct{}, 1),
+	}
+
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+
+	go a.writer()
+	return a, nil
+}
+
+func (a *accessLog) open() error {
+	file, err := os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	if a.file != nil {
+		a.buffered.Flush()
+		a.file.Close()
+	}
+	a.file = file
+	a.buffered = bufio.NewWriter(file)
+	return nil
+}
+
+// Log queues the entry, it's called by the scheduler and never blocks
+func (a *accessLog) Log(entry *accessEntry) {
+	select {
+	case a.entries <- entry:
+		if a.dropped > 0 {
+			log.Warnf("access log: dropped %d entries", a.dropped)
+			a.dropped = 0
+		}
+	default:
+		a.dropped++
+	}
+}
+
+// Reopen makes the writer reopen the file after rotated
+func (a *accessLog) Reopen() {
+	select {
+	case a.reopenc <- struct{}{}:
+	default:
+	}
+}
+
+func (a *accessLog) writer() {
+	for {
+		select {
+		case entry := <-a.entries:
+			if a.asJSON {
+				fmt.Fprintln(a.buffered, entry.json())
+			} else {
+				fmt.Fprintln(a.buffered, entry.text())
+			}
+
+			// flush once we caught up
+			if len(a.entries) == 0 {
+				a.buffered.Flush()
+			}
+		case <-a.reopenc:
+			if err := a.open(); err != nil {
+				log.Errorf("access log: %s", err)
+			}
+		}
+	}
+}
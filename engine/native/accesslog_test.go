@@ -0,0 +1,133 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package native
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessEntryFormat(t *testing.T) {
+	entry := &accessEntry{
+		Time:     time.Date(2014, 5, 6, 7, 8, 9, 0, time.Local),
+		Client:   "10.0.0.9:51234",
+		Listener: "tcp://127.0.0.1:3306",
+		Backend:  "10.0.0.1:3306",
+		Mode:     "tunnel",
+		Dial:     1500 * time.Microsecond,
+		Duration: 2 * time.Second,
+		BytesIn:  120,
+		BytesOut: 4096,
+		Retries:  1,
+	}
+
+	tests := []struct {
+		err  string
+		text string
+	}{
+		{"", "2014/05/06 07:08:09 client=10.0.0.9:51234 listener=tcp://127.0.0.1:3306 backend=10.0.0.1:3306 mode=tunnel dial=1.5ms duration=2s bytes_in=120 bytes_out=4096 retries=1"},
+		{"dial tcp: connection refused", "2014/05/06 07:08:09 client=10.0.0.9:51234 listener=tcp://127.0.0.1:3306 backend=10.0.0.1:3306 mode=tunnel dial=1.5ms duration=2s bytes_in=120 bytes_out=4096 retries=1 error=\"dial tcp: connection refused\""},
+	}
+
+	for _, tt := range tests {
+		entry.Error = tt.err
+		if text := entry.text(); text != tt.text {
+			t.Errorf("got %q, expected %q", text, tt.text)
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(entry.json()), &fields); err != nil {
+			t.Fatal(err)
+		}
+		if fields["dial_us"] != 1500.0 || fields["duration_us"] != 2e6 || fields["bytes_out"] != 4096.0 || fields["backend"] != "10.0.0.1:3306" {
+			t.Errorf("unexpected json entry %v", fields)
+		}
+		if e, ok := fields["error"]; (tt.err == "" && ok) || (tt.err != "" && e != tt.err) {
+			t.Errorf("unexpected json error %v", e)
+		}
+	}
+
+	// the entry itself keeps its durations
+	if entry.Dial != 1500*time.Microsecond {
+		t.Errorf("entry modified to %v", entry.Dial)
+	}
+}
+
+func TestAccessLogDropped(t *testing.T) {
+	// no writer drains the entries
+	a := &accessLog{entries: make(chan *accessEntry, 2)}
+
+	for i := 0; i < 5; i++ {
+		a.Log(&accessEntry{})
+	}
+	if a.dropped != 3 {
+		t.Errorf("expected 3 dropped entries, got %d", a.dropped)
+	}
+
+	// the counter is reset once an entry gets through again
+	<-a.entries
+	a.Log(&accessEntry{})
+	if a.dropped != 0 {
+		t.Errorf("expected the counter reset, got %d", a.dropped)
+	}
+}
+
+// waitLog waits for the file to contain s
+func waitLog(name, s string) string {
+	var data []byte
+	for i := 0; i < 100; i++ {
+		data, _ = ioutil.ReadFile(name)
+		if strings.Contains(string(data), s) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return string(data)
+}
+
+func TestAccessLogReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "accesslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "access.log")
+	a, err := newAccessLog(name, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.Log(&accessEntry{Backend: "before"})
+	if data := waitLog(name, "backend=before"); !strings.Contains(data, "backend=before") {
+		t.Fatalf("entry not written: %q", data)
+	}
+
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+	a.Reopen()
+	// the reopen and the entry race in the writer
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(name); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	a.Log(&accessEntry{Backend: "after"})
+
+	data := waitLog(name, "backend=after")
+	if strings.Contains(data, "before") || !strings.Contains(data, "backend=after") {
+		t.Errorf("unexpected content after reopen: %q", data)
+	}
+	if rotated, _ := ioutil.ReadFile(name + ".1"); strings.Contains(string(rotated), "after") {
+		t.Errorf("entry written to the rotated file: %q", rotated)
+	}
+}
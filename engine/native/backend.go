@@ -5,7 +5,9 @@
 package native
 
 import (
+	"crypto/tls"
 	"fmt"
+	"github.com/zhgwenming/gbalancer/proxyproto"
 	"net"
 	"net/http"
 	"sync/atomic"
@@ -24,12 +26,19 @@ var (
 )
 
 type Backend struct {
-	tunnel  []connTunnel
-	address string
-	index   int // heap related fields
-	ongoing uint
-	weight  uint // as sequence in max heap, weight in min heap
-	flags   BackendFlags
+	tunnel   []connTunnel
+	address  string
+	index    int // heap related fields
+	ongoing  uint
+	weight   uint // as sequence in max heap, weight in min heap
+	flags    BackendFlags
+	proxy    int         // PROXY protocol version, 0 if disabled
+	tls      *tls.Config // nil for the plain connections
+	status   int         // the flag from the wrangler
+	role     int         // FlagUp if it has no role
+	capacity uint        // relative weight, the ongoing ones are scaled by it
+	draining bool        // kept out of the heap for the new connections
+	outlier  outlierState
 
 	failChan chan<- *spdySession
 	tunnels  uint
@@ -48,10 +57,22 @@ func NewBackend(addr string, tunnels uint, weight uint) *Backend {
 		tunnels: tunnels,
 		flags:   FlagInit,
 	}
+	b.setStatus(FlagUp)
 
 	return b
 }
 
+// setStatus applies the role, weight and drain state from the wrangler
+func (b *Backend) setStatus(flag int) {
+	b.status = flag
+	b.role = flag & RoleMask
+	b.draining = flag&FlagDrain != 0
+	b.capacity = uint(flag >> WeightShift)
+	if b.capacity == 0 {
+		b.capacity = DefaultWeight
+	}
+}
+
 func (b *Backend) SwitchSpdyConn(index uint, to *connTunnel) {
 	if from := b.tunnel[index].conn; from != nil {
 		from.Close()
@@ -85,7 +106,7 @@ func (b *Backend) SpdyCheckStreamId(backChan chan<- *spdySession) {
 			if !tunnel[index].switching {
 				// check to see if the spdyConn needed to be switched
 				if uint32(tunnel[index].conn.PeekNextStreamId()) > ThreshStreamId {
-					log.Printf("pre-create new session for %s", b.address)
+					log.Debugf("pre-create new session for %s", b.address)
 					tunnel[index].switching = true
 					go CreateSpdySession(NewSpdySession(b, index), backChan)
 				}
@@ -97,8 +118,21 @@ func (b *Backend) SpdyCheckStreamId(backChan chan<- *spdySession) {
 // Runs inside of Forwarder goroutine
 // takeoff the spdyconn if it's broken
 func (b *Backend) ForwarderNewConnection(req *Request) (net.Conn, error) {
+	req.tunnel = false
+
+	var proxy *proxyproto.Header
+	if b.proxy > 0 {
+		proxy = proxyHeader(req, b.proxy)
+	}
+
 	if b.tunnels <= 0 {
-		return net.Dial("tcp", req.backend.address)
+		return dial(b.address, proxy, b.tls)
+	}
+
+	// the other end of the tunnel sends the PROXY header to the service
+	header := http.Header{}
+	if proxy != nil {
+		header = proxy.HTTPHeader()
 	}
 
 	var found bool
@@ -113,7 +147,7 @@ func (b *Backend) ForwarderNewConnection(req *Request) (net.Conn, error) {
 
 		if spdyconn != nil {
 			found = true
-			conn, err = spdyconn.CreateStream(http.Header{}, nil, false)
+			conn, err = spdyconn.CreateStream(header, nil, false)
 			if err != nil {
 				spdyptr := (*unsafe.Pointer)(unsafe.Pointer(&b.tunnel[index].conn))
 
@@ -121,9 +155,9 @@ func (b *Backend) ForwarderNewConnection(req *Request) (net.Conn, error) {
 				if swapped {
 					if conn == nil {
 						// streamId used up
-						log.Printf("Used up streamdID. (%s)", err)
+						log.Warnf("Used up streamdID. (%s)", err)
 					} else {
-						log.Printf("Failed to create stream. (%s)", err)
+						log.Errorf("Failed to create stream. (%s)", err)
 					}
 
 					// try to close exist session
@@ -131,6 +165,7 @@ func (b *Backend) ForwarderNewConnection(req *Request) (net.Conn, error) {
 					b.failChan <- NewSpdySession(b, index)
 				}
 			} else {
+				req.tunnel = true
 				break
 			}
 		}
@@ -140,11 +175,44 @@ func (b *Backend) ForwarderNewConnection(req *Request) (net.Conn, error) {
 		// just log error if we have at lease one connection in the tunnel
 		// if we don't, just fall back to tcp mode silently
 		if found {
-			log.Printf("Failed to create stream, rolling back to tcp mode. (%s)", err)
+			log.Warnf("Failed to create stream, rolling back to tcp mode. (%s)", err)
 		}
-		conn, err = net.Dial("tcp", req.backend.address)
+		conn, err = dial(b.address, proxy, b.tls)
 	}
 
 	return conn, err
 
 }
+
+// proxyHeader describes the client connection, the addresses come from the
+// PROXY header of the upstream balancer if the listener accepts one, it got
+// read on accept
+func proxyHeader(req *Request, version int) *proxyproto.Header {
+	return proxyproto.NewHeader(version, req.Conn.RemoteAddr(), req.Conn.LocalAddr())
+}
+
+// dial connects to the backend, sends the PROXY header if there's one and
+// starts TLS if it's enabled
+func dial(addr string, proxy *proxyproto.Header, config *tls.Config) (net.Conn, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return conn, err
+	}
+
+	if proxy != nil {
+		if _, err := proxy.WriteTo(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if config != nil {
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("tls handshake with %s: %s", addr, err)
+		}
+		conn = tlsConn
+	}
+	return conn, nil
+}
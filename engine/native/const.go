@@ -4,6 +4,10 @@
 
 package native
 
+import (
+	"time"
+)
+
 const (
 	MaxBackends             uint   = 128
 	MaxForwarders           uint   = 8192
@@ -17,11 +21,43 @@ const (
 	DEFAULT_UNIX_SOCKET = "/var/lib/mysql/mysql.sock"
 )
 
+// the MySQL greeting of the rejected clients
+const (
+	RejectVersion = "5.7.0-gbalancer"
+	RejectTimeout = 10 * time.Second
+)
+
+const (
+	// below the wait_timeout of the servers
+	DefaultPoolIdleTimeout = 60 * time.Second
+)
+
+// the roles reported by the wrangler with the up flag, the draining
+// backends get FlagDrain and the weight goes above WeightShift
+const (
+	FlagDown    int = 0
+	FlagUp      int = 1
+	FlagPrimary int = 2
+	FlagReplica int = 3
+	RoleMask    int = 0xff
+	FlagDrain   int = 1 << 8
+	WeightShift     = 16
+)
+
 const (
-	FlagDown int = 0
-	FlagUp   int = 1
+	DefaultWeight uint = 100
 )
 
 const (
 	ReqRefused int = 1
 )
+
+// defaults of the outlier detection
+const (
+	OutlierMinConnections    = 10
+	OutlierInterval          = 10 * time.Second
+	OutlierBaseEjection      = 30 * time.Second
+	OutlierMaxEjection       = 5 * time.Minute
+	OutlierMaxEjectedPercent = 50
+	OutlierTick              = time.Second
+)
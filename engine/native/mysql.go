@@ -0,0 +1,717 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package native
+
+import (
+	"errors"
+	"fmt"
+	"github.com/zhgwenming/gbalancer/config"
+	"github.com/zhgwenming/gbalancer/mysqlproto"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	mysqlConnectionId uint32
+)
+
+type queryKind int
+
+const (
+	queryWrite queryKind = iota
+	queryRead
+	// runs on the writer and gets replayed on the reader before the reads
+	querySession
+	// ties the rest of the session to the writer
+	queryPin
+)
+
+type mysqlSettings struct {
+	// the clients log in with the same users as the backends
+	users   map[string]string
+	version string
+	split   bool
+
+	// pooling of the backend connections is on if maxIdle > 0
+	maxIdle     int
+	maxOpen     int
+	idleTimeout time.Duration
+
+	sync.Mutex
+	pools         map[string]*mysqlPool
+	serverVersion string // of the backends, to greet the clients with
+}
+
+func newMySQLSettings(c *config.MySQLConfig) (*mysqlSettings, error) {
+	if len(c.Users) == 0 {
+		return nil, fmt.Errorf("MySQL.Users is required for the protocol aware mode")
+	}
+
+	m := &mysqlSettings{
+		users:         c.Users,
+		version:       c.ServerVersion,
+		split:         c.Split,
+		maxIdle:       c.Pool.MaxIdle,
+		maxOpen:       c.Pool.MaxConnections,
+		idleTimeout:   DefaultPoolIdleTimeout,
+		pools:         make(map[string]*mysqlPool),
+		serverVersion: RejectVersion,
+	}
+
+	if c.Pool.IdleTimeout != "" {
+		timeout, err := time.ParseDuration(c.Pool.IdleTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("MySQL.Pool.IdleTimeout: %s", err)
+		}
+		m.idleTimeout = timeout
+	}
+	return m, nil
+}
+
+func (m *mysqlSettings) password(user string) (string, bool) {
+	pass, ok := m.users[user]
+	return pass, ok
+}
+
+// mysqlErrors are sent to the clients the scheduler turns down
+type mysqlErrors struct {
+	version   string
+	noBackend *mysqlproto.Error
+	busy      *mysqlproto.Error
+}
+
+func newMySQLErrors(c *config.MySQLConfig) *mysqlErrors {
+	e := &mysqlErrors{
+		RejectVersion,
+		&mysqlproto.Error{Code: mysqlproto.CR_CONN_HOST_ERROR, State: "HY000", Message: "No healthy backend available"},
+		&mysqlproto.Error{Code: mysqlproto.ER_CON_COUNT_ERROR, State: "08004", Message: "Too many connections"},
+	}
+
+	if c.ServerVersion != "" {
+		e.version = c.ServerVersion
+	}
+	if c.NoBackend.Code != 0 {
+		e.noBackend.Code = c.NoBackend.Code
+	}
+	if c.NoBackend.Message != "" {
+		e.noBackend.Message = c.NoBackend.Message
+	}
+	if c.Busy.Code != 0 {
+		e.busy.Code = c.Busy.Code
+	}
+	if c.Busy.Message != "" {
+		e.busy.Message = c.Busy.Message
+	}
+	return e
+}
+
+// rejectMySQL runs the handshake of the client to send it the error
+func rejectMySQL(conn net.Conn, version string, e *mysqlproto.Error) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(RejectTimeout))
+	if err := mysqlproto.Reject(mysqlproto.NewConn(conn), version, e); err != nil {
+		log.Debugf("mysql: failed to reject %s: %s", conn.RemoteAddr(), err)
+	}
+}
+
+// countedConn counts the bytes of the client for the access log
+type countedConn struct {
+	net.Conn
+	in  int64
+	out int64
+}
+
+func (c *countedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.in += int64(n)
+	return n, err
+}
+
+func (c *countedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.out += int64(n)
+	return n, err
+}
+
+type mysqlSession struct {
+	req      *Request
+	settings *mysqlSettings
+	counted  *countedConn
+	client   *mysqlproto.Conn
+	writer   *backendConn
+	reader   *backendConn // opened on the first read
+	noReader bool         // the reader failed, stay on the writer
+	pinned   bool         // passed through to the writer
+	login    *mysqlproto.Login
+
+	// status of the writer
+	status uint16
+
+	// the session state to replay on the reader
+	state     []string
+	replayed  int
+	changedDB bool
+}
+
+// backendError is a backend failing to log the client in, it's taken out
+// like on a failed dial
+type backendError struct {
+	error
+}
+
+func (s *Scheduler) runMySQL(req *Request) {
+	// the client logged in to us already if it's retried
+	session := req.session
+	if session == nil {
+		session = &mysqlSession{
+			req:      req,
+			settings: s.mysql,
+			counted:  &countedConn{Conn: req.Conn},
+		}
+		session.client = mysqlproto.NewConn(session.counted)
+
+		// without pooling the writer is dialed first so the dial errors
+		// are retried on the other backends
+		if s.mysql.pool(req.backend.address) == nil {
+			dialStart := time.Now()
+			srv, err := req.backend.ForwarderNewConnection(req)
+			req.dialTime = time.Since(dialStart)
+			if err != nil {
+				req.err = err
+				s.done <- req
+				return
+			}
+			session.writer = &backendConn{Conn: mysqlproto.NewConn(srv)}
+		}
+	}
+
+	err := session.serve()
+	req.bytesIn, req.bytesOut = session.counted.in, session.counted.out
+
+	var failed backendError
+	switch {
+	case isDialError(err):
+		// the pooled writer gets dialed on another backend
+		req.session = session
+		req.err = err
+	case errors.As(err, &failed):
+		session.close(false)
+		req.err = failed.error
+	default:
+		session.close(err == nil)
+		req.copyErr = err
+	}
+	s.done <- req
+}
+
+func (m *mysqlSession) close(clean bool) {
+	m.client.Close()
+	if m.writer != nil {
+		m.recycle(m.writer, clean)
+	}
+	if m.reader != nil {
+		m.recycle(m.reader, clean)
+	}
+}
+
+// fail tells the client logged in to us that no backend is available
+func (m *mysqlSession) fail() {
+	m.client.WriteError(mysqlproto.CR_CONN_HOST_ERROR, "HY000", "Backend is not available")
+	m.client.Close()
+}
+
+// serve authenticates the client, logs in to the writer on behalf of it
+// and routes the commands until the client quits
+func (m *mysqlSession) serve() error {
+	if m.login == nil {
+		if err := m.accept(); err != nil {
+			return err
+		}
+	}
+
+	if m.writer == nil {
+		dialStart := time.Now()
+		writer, err := m.acquire(m.req.backend)
+		m.req.dialTime = time.Since(dialStart)
+		if err != nil {
+			return m.loginError(err)
+		}
+		m.writer = writer
+	}
+
+	m.status = mysqlproto.SERVER_STATUS_AUTOCOMMIT
+	if err := m.client.WriteOK(m.status); err != nil {
+		return err
+	}
+
+	return m.loop()
+}
+
+// accept authenticates the client, and logs in to the writer already
+// dialed on behalf of it
+func (m *mysqlSession) accept() error {
+	var h *mysqlproto.Handshake
+	version := m.settings.version
+
+	if m.writer != nil {
+		var err error
+		if h, err = mysqlproto.ReadHandshake(m.writer.Conn); err != nil {
+			return m.loginError(err)
+		}
+		if version == "" {
+			version = h.Version
+		}
+	} else if version == "" {
+		version = m.settings.backendVersion()
+	}
+
+	id := atomic.AddUint32(&mysqlConnectionId, 1)
+	resp, err := mysqlproto.Accept(m.client, version, id, m.settings.password)
+	if err != nil {
+		return err
+	}
+
+	pass, _ := m.settings.password(resp.User)
+	m.login = &mysqlproto.Login{
+		User:         resp.User,
+		Password:     pass,
+		Database:     resp.Database,
+		Charset:      resp.Charset,
+		Capabilities: resp.Capabilities,
+	}
+
+	if m.writer != nil {
+		if err := mysqlproto.Authenticate(m.writer.Conn, h, m.login); err != nil {
+			return m.loginError(err)
+		}
+	}
+	return nil
+}
+
+// loginError tells the client the login to the backend failed, the failed
+// dial is kept from it to be retried on another backend
+func (m *mysqlSession) loginError(err error) error {
+	if e, ok := err.(*mysqlproto.Error); ok {
+		m.client.WritePacket(e.Packet())
+		return err
+	}
+	if isDialError(err) {
+		return err
+	}
+
+	m.client.WriteError(mysqlproto.CR_CONN_HOST_ERROR, "HY000", "Backend is not available")
+	return backendError{err}
+}
+
+func (m *mysqlSession) loop() error {
+	for {
+		cmd, err := m.client.ReadCommand()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		switch cmd[0] {
+		case mysqlproto.COM_QUIT:
+			return nil
+		case mysqlproto.COM_QUERY:
+			err = m.query(cmd)
+		case mysqlproto.COM_INIT_DB:
+			var known bool
+			if known, err = m.simple(cmd); err == nil && known {
+				db := strings.Replace(string(cmd[1:]), "`", "``", -1)
+				m.record("USE `" + db + "`")
+			}
+		case mysqlproto.COM_PING, mysqlproto.COM_STATISTICS, mysqlproto.COM_REFRESH,
+			mysqlproto.COM_PROCESS_KILL, mysqlproto.COM_DEBUG:
+			_, err = m.simple(cmd)
+		case mysqlproto.COM_FIELD_LIST:
+			if err = m.writer.WriteCommand(cmd); err == nil {
+				err = m.writer.ReadFieldList(m.client.WritePacket)
+			}
+		case mysqlproto.COM_RESET_CONNECTION:
+			err = m.reset(cmd)
+		case mysqlproto.COM_CHANGE_USER:
+			err = m.client.WriteError(mysqlproto.ER_NOT_SUPPORTED_YET, "42000",
+				"COM_CHANGE_USER is not supported by the proxy")
+		default:
+			// prepared statements and the rest stay on the writer
+			return m.pin(cmd)
+		}
+
+		if err != nil || m.pinned {
+			return err
+		}
+	}
+}
+
+func (m *mysqlSession) query(cmd []byte) error {
+	switch classify(string(cmd[1:])) {
+	case queryRead:
+		if !m.inTransaction() {
+			return m.read(cmd)
+		}
+	case querySession:
+		known, err := m.write(cmd)
+		if err == nil && known {
+			m.record(string(cmd[1:]))
+		}
+		return err
+	case queryPin:
+		return m.pin(cmd)
+	}
+
+	_, err := m.write(cmd)
+	return err
+}
+
+func (m *mysqlSession) inTransaction() bool {
+	return m.status&mysqlproto.SERVER_STATUS_IN_TRANS != 0 ||
+		m.status&mysqlproto.SERVER_STATUS_AUTOCOMMIT == 0
+}
+
+// write runs the command on the writer and relays the response, it tells
+// whether the command succeeded
+func (m *mysqlSession) write(cmd []byte) (bool, error) {
+	if err := m.writer.WriteCommand(cmd); err != nil {
+		return false, err
+	}
+
+	status, known, err := m.writer.ReadResult(m.client.WritePacket)
+	if known {
+		m.status = status
+	}
+	return known, err
+}
+
+func (m *mysqlSession) simple(cmd []byte) (bool, error) {
+	if err := m.writer.WriteCommand(cmd); err != nil {
+		return false, err
+	}
+
+	status, known, err := m.writer.ReadSimple(m.client.WritePacket)
+	if known {
+		m.status = status
+	}
+	return known, err
+}
+
+// read runs the query on the reader, it falls back to the writer if the
+// reader failed before anything got relayed to the client
+func (m *mysqlSession) read(cmd []byte) error {
+	reader := m.readerConn()
+	if reader == nil {
+		_, err := m.write(cmd)
+		return err
+	}
+
+	var relayed bool
+	err := reader.WriteCommand(cmd)
+	if err == nil {
+		_, _, err = reader.ReadResult(func(p []byte) error {
+			relayed = true
+			return m.client.WritePacket(p)
+		})
+	}
+
+	if err != nil && !relayed {
+		m.dropReader(err)
+		_, err = m.write(cmd)
+	}
+	return err
+}
+
+// readerConn returns the reader with the session state of the writer,
+// nil if the reads should go to the writer
+func (m *mysqlSession) readerConn() *mysqlproto.Conn {
+	if m.noReader {
+		return nil
+	}
+
+	if m.reader == nil {
+		b := m.req.reader
+		if b == nil || b == m.req.backend {
+			m.noReader = true
+			return nil
+		}
+
+		reader, err := m.acquire(b)
+		if err != nil {
+			log.Warnf("mysql: failed to connect reader %s: %s", b.address, err)
+			m.noReader = true
+			return nil
+		}
+		m.reader = reader
+		m.replayed = 0
+	}
+
+	for m.replayed < len(m.state) {
+		query := m.state[m.replayed]
+		err := m.reader.WriteCommand(append([]byte{mysqlproto.COM_QUERY}, query...))
+		if err == nil {
+			var known bool
+			if _, known, err = m.reader.ReadResult(nil); err == nil && !known {
+				err = fmt.Errorf("failed to replay %q", query)
+			}
+		}
+
+		if err != nil {
+			m.dropReader(err)
+			return nil
+		}
+		m.replayed++
+	}
+
+	return m.reader.Conn
+}
+
+func (m *mysqlSession) dropReader(err error) {
+	log.Warnf("mysql: stop reading from %s: %s", m.req.reader.address, err)
+	if m.reader != nil {
+		m.reader.Close()
+		m.reader = nil
+	}
+	m.noReader = true
+}
+
+// record keeps the statement for the reader, the repeated ones like
+// SET NAMES of the connection pools are moved to the end
+func (m *mysqlSession) record(query string) {
+	for i, q := range m.state {
+		if q == query {
+			m.state = append(m.state[:i], m.state[i+1:]...)
+			if i < m.replayed {
+				m.replayed--
+			}
+			break
+		}
+	}
+	m.state = append(m.state, query)
+
+	if first, _ := firstWord(query); first == "USE" {
+		m.changedDB = true
+	}
+}
+
+// reset clears the session state on all the backend connections, the
+// current database survives the reset
+func (m *mysqlSession) reset(cmd []byte) error {
+	if m.reader != nil {
+		err := m.reader.WriteCommand(cmd)
+		if err == nil {
+			var known bool
+			if _, known, err = m.reader.ReadSimple(nil); err == nil && !known {
+				err = fmt.Errorf("failed to reset the connection")
+			}
+		}
+		if err != nil {
+			m.dropReader(err)
+		}
+	}
+
+	known, err := m.simple(cmd)
+	if err == nil && known {
+		var state []string
+		for _, q := range m.state {
+			if first, _ := firstWord(q); first == "USE" {
+				state = append(state, q)
+			}
+		}
+		m.state = state
+		m.replayed = 0
+	}
+	return err
+}
+
+// pin passes the rest of the session through to the writer, it keeps the
+// state the proxy doesn't track like the prepared statements
+func (m *mysqlSession) pin(cmd []byte) error {
+	if m.reader != nil {
+		m.reader.Close()
+		m.reader = nil
+	}
+	m.noReader = true
+	m.pinned = true
+
+	if err := m.writer.WriteCommand(cmd); err != nil {
+		return err
+	}
+
+	c := make(chan *copyRet, 2)
+	go sockCopy(m.client, m.writer.Reader(), false, c)
+	go sockCopy(m.writer, m.client.Reader(), true, c)
+
+	var err error
+	for i := 0; i < 2; i++ {
+		r := <-c
+		if r.err != nil && !errors.Is(r.err, net.ErrClosed) && err == nil {
+			err = r.err
+		}
+	}
+	return err
+}
+
+var (
+	lockFunctions = []string{"GET_LOCK(", "RELEASE_LOCK(", "RELEASE_ALL_LOCKS(", "IS_USED_LOCK(", "IS_FREE_LOCK("}
+
+	// the selects which need the writer or the session of it
+	writerSelects = []string{" FOR UPDATE", " FOR SHARE", " LOCK IN SHARE MODE", " INTO ",
+		"SQL_CALC_FOUND_ROWS", "LAST_INSERT_ID(", "FOUND_ROWS(", "ROW_COUNT(", "NEXTVAL(",
+		"NEXT VALUE FOR", "@@IDENTITY", "@@LAST_INSERT_ID"}
+
+	// the sets which aren't session state or are tracked by the status
+	writerSets = []string{"GLOBAL", "PERSIST", "AUTOCOMMIT", "TRANSACTION", "PASSWORD"}
+)
+
+// classify tells where a query goes
+func classify(query string) queryKind {
+	q, executable := normalizeQuery(query)
+	if executable {
+		// versioned comments of the dumps, can't tell what's in them
+		return queryPin
+	}
+
+	q = strings.TrimRight(q, "; ")
+	if !strings.Contains(q, ";") {
+		return classifyStatement(q)
+	}
+
+	// multi statements go to the writer
+	for _, stmt := range strings.Split(q, ";") {
+		switch classifyStatement(strings.TrimSpace(stmt)) {
+		case queryPin, querySession:
+			return queryPin
+		}
+	}
+	return queryWrite
+}
+
+func classifyStatement(q string) queryKind {
+	if userVariable(q) || containsAny(q, lockFunctions) || strings.Contains(q, "TEMPORARY") {
+		return queryPin
+	}
+
+	word, _ := firstWord(q)
+	switch word {
+	case "LOCK", "UNLOCK", "PREPARE", "EXECUTE", "DEALLOCATE", "HANDLER":
+		return queryPin
+	case "SELECT":
+		if containsAny(q, writerSelects) {
+			return queryWrite
+		}
+		return queryRead
+	case "SET":
+		if containsAny(q, writerSets) {
+			return queryWrite
+		}
+		return querySession
+	case "USE":
+		return querySession
+	}
+	return queryWrite
+}
+
+func firstWord(query string) (string, string) {
+	q, _ := normalizeQuery(query)
+	q = strings.TrimSpace(q)
+	if i := strings.IndexByte(q, ' '); i != -1 {
+		return q[:i], q[i+1:]
+	}
+	return q, ""
+}
+
+// userVariable tells if there's a @var, the @@system ones are fine
+func userVariable(q string) bool {
+	for i := 0; i < len(q); i++ {
+		if q[i] != '@' {
+			continue
+		}
+		if i+1 < len(q) && q[i+1] == '@' {
+			i++
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func containsAny(q string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(q, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeQuery upper cases the query, drops the comments, replaces the
+// quoted strings and identifiers with ? and collapses the spaces. It
+// reports the executable comments instead.
+func normalizeQuery(q string) (string, bool) {
+	b := make([]byte, 0, len(q))
+	space := false
+
+	for i := 0; i < len(q); i++ {
+		c := q[i]
+		switch {
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			if strings.HasPrefix(q[i:], "/*!") {
+				return "", true
+			}
+			if end := strings.Index(q[i+2:], "*/"); end != -1 {
+				i += end + 3
+			} else {
+				i = len(q)
+			}
+			space = true
+			continue
+		case c == '#' || (c == '-' && strings.HasPrefix(q[i:], "--") &&
+			(i+2 == len(q) || q[i+2] == ' ' || q[i+2] == '\t' || q[i+2] == '\n')):
+			if end := strings.IndexByte(q[i:], '\n'); end != -1 {
+				i += end
+			} else {
+				i = len(q)
+			}
+			space = true
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for ; j < len(q); j++ {
+				if q[j] == '\\' && c != '`' {
+					j++
+				} else if q[j] == c {
+					if j+1 < len(q) && q[j+1] == c {
+						j++
+					} else {
+						break
+					}
+				}
+			}
+			i = j
+			c = '?'
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		}
+
+		// GET_LOCK ( is a call with IGNORE_SPACE
+		if space && len(b) > 0 && c != '(' {
+			b = append(b, ' ')
+		}
+		space = false
+		b = append(b, c)
+	}
+
+	return string(b), false
+}
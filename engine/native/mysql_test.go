@@ -0,0 +1,479 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package native
+
+import (
+	"github.com/zhgwenming/gbalancer/config"
+	"github.com/zhgwenming/gbalancer/mysqlproto"
+	"github.com/zhgwenming/gbalancer/proxyproto"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	queries := map[string]queryKind{
+		"SELECT * FROM t": queryRead,
+		"  select id from t where name = 'FOR UPDATE'":   queryRead,
+		"/* route */ SELECT 1":                           queryRead,
+		"SELECT @@version":                               queryRead,
+		"SELECT * FROM t FOR UPDATE":                     queryWrite,
+		"select * from t lock in share mode":             queryWrite,
+		"SELECT LAST_INSERT_ID()":                        queryWrite,
+		"SELECT SQL_CALC_FOUND_ROWS * FROM t LIMIT 1":    queryWrite,
+		"INSERT INTO t VALUES (1)":                       queryWrite,
+		"BEGIN":                                          queryWrite,
+		"SET autocommit=0":                               queryWrite,
+		"SET GLOBAL max_connections = 10":                queryWrite,
+		"SET TRANSACTION ISOLATION LEVEL READ COMMITTED": queryWrite,
+		"SELECT 1; SELECT 2":                             queryWrite,
+		"SET NAMES utf8":                                 querySession,
+		"set @@session.sql_mode = ''":                    querySession,
+		"USE db":                                         querySession,
+		"SET @a = 1":                                     queryPin,
+		"SELECT @a":                                      queryPin,
+		"SELECT GET_LOCK ('x', 1)":                       queryPin,
+		"LOCK TABLES t READ":                             queryPin,
+		"CREATE TEMPORARY TABLE t (id int)":              queryPin,
+		"PREPARE s FROM 'SELECT 1'":                      queryPin,
+		"SELECT 1; SET NAMES utf8":                       queryPin,
+		"/*!40101 SET NAMES utf8 */":                     queryPin,
+	}
+
+	for q, expected := range queries {
+		if kind := classify(q); kind != expected {
+			t.Errorf("%q: got %d, expected %d", q, kind, expected)
+		}
+	}
+}
+
+// fakeMySQL answers the selects with its name and records the queries
+type fakeMySQL struct {
+	name     string
+	listener net.Listener
+
+	sync.Mutex
+	queries  []string
+	accepted int
+	sources  []string // the client hosts
+}
+
+func newFakeMySQL(t *testing.T, name string) *fakeMySQL {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serveFakeMySQL(name, l)
+}
+
+// newProxiedFakeMySQL expects a PROXY header on every connection
+func newProxiedFakeMySQL(t *testing.T, name string) *fakeMySQL {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serveFakeMySQL(name, proxyproto.NewListener(l))
+}
+
+func serveFakeMySQL(name string, l net.Listener) *fakeMySQL {
+	f := &fakeMySQL{name: name, listener: l}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			// the address comes from the PROXY header once it's read
+			if c, ok := conn.(*proxyproto.Conn); ok {
+				c.Header()
+			}
+			f.Lock()
+			f.accepted++
+			f.sources = append(f.sources, clientHost(conn))
+			f.Unlock()
+			go f.serve(mysqlproto.NewConn(conn))
+		}
+	}()
+	return f
+}
+
+func (f *fakeMySQL) serve(c *mysqlproto.Conn) {
+	defer c.Close()
+
+	users := func(user string) (string, bool) {
+		return "secret", user == "app"
+	}
+	if _, err := mysqlproto.Accept(c, "5.7.0-fake", 1, users); err != nil {
+		return
+	}
+
+	status := mysqlproto.SERVER_STATUS_AUTOCOMMIT
+	c.WriteOK(status)
+
+	for {
+		cmd, err := c.ReadCommand()
+		if err != nil || cmd[0] == mysqlproto.COM_QUIT {
+			return
+		}
+
+		if cmd[0] != mysqlproto.COM_QUERY {
+			c.WriteOK(status)
+			continue
+		}
+
+		query := string(cmd[1:])
+		f.Lock()
+		f.queries = append(f.queries, query)
+		f.Unlock()
+
+		switch strings.ToUpper(query) {
+		case "BEGIN":
+			status |= mysqlproto.SERVER_STATUS_IN_TRANS
+		case "COMMIT":
+			status &^= mysqlproto.SERVER_STATUS_IN_TRANS
+		}
+
+		if !strings.HasPrefix(strings.ToUpper(query), "SELECT") {
+			c.WriteOK(status)
+			continue
+		}
+
+		eof := []byte{mysqlproto.EOF_PACKET, 0, 0, byte(status), byte(status >> 8)}
+		c.WritePacket([]byte{1})
+		c.WritePacket([]byte("\x03def\x00\x00\x00\x01v\x00\x0c\x21\x00\x00\x01\x00\x00\xfd\x00\x00\x00\x00\x00"))
+		c.WritePacket(eof)
+		c.WritePacket(append([]byte{byte(len(f.name))}, f.name...))
+		c.WritePacket(eof)
+	}
+}
+
+func (f *fakeMySQL) received() []string {
+	f.Lock()
+	defer f.Unlock()
+	return append([]string{}, f.queries...)
+}
+
+// query returns the value of the single row or "OK"
+func query(t *testing.T, c *mysqlproto.Conn, q string) string {
+	if err := c.WriteCommand(append([]byte{mysqlproto.COM_QUERY}, q...)); err != nil {
+		t.Fatal(err)
+	}
+
+	var packets [][]byte
+	_, known, err := c.ReadResult(func(p []byte) error {
+		packets = append(packets, p)
+		return nil
+	})
+	if err != nil || !known {
+		t.Fatalf("%q failed: %v", q, err)
+	}
+
+	if len(packets) == 1 {
+		return "OK"
+	}
+	return string(packets[3][1:])
+}
+
+func tcpPair(t *testing.T) (net.Conn, net.Conn) {
+	return tcpPairFrom(t, "127.0.0.1")
+}
+
+// tcpPairFrom connects from the host, any of 127.0.0.0/8 works on linux
+func tcpPairFrom(t *testing.T, host string) (net.Conn, net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	dialer := net.Dialer{LocalAddr: &net.TCPAddr{IP: net.ParseIP(host)}}
+	client, err := dialer.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, client
+}
+
+func newMySQLScheduler(c *config.MySQLConfig) *Scheduler {
+	c.Users = map[string]string{"app": "secret"}
+	s := &Scheduler{done: make(chan *Request, 1)}
+	s.mysql, _ = newMySQLSettings(c)
+	return s
+}
+
+// startSession runs a session on the backends, the reader is optional
+func startSession(t *testing.T, s *Scheduler, writer, reader *Backend, password string) (*mysqlproto.Conn, error) {
+	return startSessionFrom(t, s, "127.0.0.1", writer, reader, password)
+}
+
+func startSessionFrom(t *testing.T, s *Scheduler, host string, writer, reader *Backend, password string) (*mysqlproto.Conn, error) {
+	proxySide, clientSide := tcpPairFrom(t, host)
+	req := &Request{Conn: proxySide, start: time.Now(), backend: writer, reader: reader}
+	go s.run(req)
+
+	client := mysqlproto.NewConn(clientSide)
+	_, err := mysqlproto.Connect(client, &mysqlproto.Login{User: "app", Password: password, Charset: mysqlproto.DefaultCharset})
+	return client, err
+}
+
+func (f *fakeMySQL) backend() *Backend {
+	return NewBackend(f.listener.Addr().String(), 0, 0)
+}
+
+func TestMySQLSplit(t *testing.T) {
+	writer, reader := newFakeMySQL(t, "writer"), newFakeMySQL(t, "reader")
+	defer writer.listener.Close()
+	defer reader.listener.Close()
+
+	s := newMySQLScheduler(&config.MySQLConfig{Split: true})
+	if _, err := startSession(t, s, writer.backend(), reader.backend(), "wrong"); err == nil {
+		t.Fatal("logged in with a wrong password")
+	}
+	<-s.done
+
+	client, err := startSession(t, s, writer.backend(), reader.backend(), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT v FROM t", "reader"},
+		{"SET NAMES utf8", "OK"},
+		{"INSERT INTO t VALUES (1)", "OK"},
+		{"SELECT v FROM t", "reader"},
+		{"BEGIN", "OK"},
+		{"SELECT v FROM t", "writer"},
+		{"COMMIT", "OK"},
+		{"SELECT v FROM t", "reader"},
+		{"SELECT GET_LOCK('x', 1)", "writer"},
+		{"SELECT v FROM t", "writer"},
+	}
+
+	for _, step := range steps {
+		if got := query(t, client, step.query); got != step.expected {
+			t.Fatalf("%q: got %s, expected %s", step.query, got, step.expected)
+		}
+	}
+
+	client.WriteCommand([]byte{mysqlproto.COM_QUIT})
+	if req := <-s.done; req.copyErr != nil {
+		t.Fatal(req.copyErr)
+	}
+
+	expected := "SELECT v FROM t,SET NAMES utf8,SELECT v FROM t,SELECT v FROM t"
+	if got := strings.Join(reader.received(), ","); got != expected {
+		t.Errorf("reader got %s", got)
+	}
+
+	expected = "SET NAMES utf8,INSERT INTO t VALUES (1),BEGIN,SELECT v FROM t,COMMIT,SELECT GET_LOCK('x', 1),SELECT v FROM t"
+	if got := strings.Join(writer.received(), ","); got != expected {
+		t.Errorf("writer got %s", got)
+	}
+}
+
+func TestMySQLNoBackend(t *testing.T) {
+	s := NewScheduler(false, 0)
+	s.mysqlErrors = newMySQLErrors(&config.MySQLConfig{NoBackend: config.MySQLError{Message: "no healthy galera node"}})
+
+	// the clients wait for the first backends on start
+	waiting, _ := tcpPair(t)
+	s.dispatch(&Request{Conn: waiting, start: time.Now()})
+	if len(s.pending) != 1 {
+		t.Fatal("the request isn't left pending on start")
+	}
+	s.pending = s.pending[:0]
+	waiting.Close()
+
+	s.checked = true
+	proxySide, clientSide := tcpPair(t)
+	s.dispatch(&Request{Conn: proxySide, start: time.Now()})
+
+	_, err := mysqlproto.Connect(mysqlproto.NewConn(clientSide), &mysqlproto.Login{User: "app"})
+	if e, ok := err.(*mysqlproto.Error); !ok || e.Code != mysqlproto.CR_CONN_HOST_ERROR || e.Message != "no healthy galera node" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(s.pending) != 0 {
+		t.Fatal("the request is left pending")
+	}
+}
+
+func TestMySQLPool(t *testing.T) {
+	f := newFakeMySQL(t, "writer")
+	defer f.listener.Close()
+
+	s := newMySQLScheduler(&config.MySQLConfig{Pool: config.MySQLPool{MaxIdle: 1, MaxConnections: 1}})
+	b := f.backend()
+
+	for i := 0; i < 3; i++ {
+		client, err := startSession(t, s, b, nil, "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := query(t, client, "SELECT v FROM t"); got != "writer" {
+			t.Fatalf("got %s", got)
+		}
+
+		// over the limit while the connection is in use
+		if i == 0 {
+			if _, err := startSession(t, s, b, nil, "secret"); err == nil {
+				t.Fatal("exceeded the limit of the backend")
+			}
+			<-s.done
+		}
+
+		client.WriteCommand([]byte{mysqlproto.COM_QUIT})
+		if req := <-s.done; req.copyErr != nil {
+			t.Fatal(req.copyErr)
+		}
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	if f.accepted != 1 {
+		t.Fatalf("%d connections to the backend", f.accepted)
+	}
+}
+
+func TestMySQLPoolProxy(t *testing.T) {
+	f := newProxiedFakeMySQL(t, "writer")
+	defer f.listener.Close()
+
+	s := newMySQLScheduler(&config.MySQLConfig{Pool: config.MySQLPool{MaxIdle: 2}})
+	b := f.backend()
+	b.proxy = 1
+
+	// the second host doesn't get the connection of the first one
+	for _, host := range []string{"127.0.0.1", "127.0.0.2", "127.0.0.1"} {
+		client, err := startSessionFrom(t, s, host, b, nil, "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := query(t, client, "SELECT v FROM t"); got != "writer" {
+			t.Fatalf("got %s", got)
+		}
+
+		client.WriteCommand([]byte{mysqlproto.COM_QUIT})
+		if req := <-s.done; req.copyErr != nil {
+			t.Fatal(req.copyErr)
+		}
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	if got := strings.Join(f.sources, ","); got != "127.0.0.1,127.0.0.2" {
+		t.Fatalf("the backend got the connections of %s", got)
+	}
+}
+
+func TestMySQLPoolDialError(t *testing.T) {
+	f := newFakeMySQL(t, "writer")
+	defer f.listener.Close()
+
+	s := newMySQLScheduler(&config.MySQLConfig{Pool: config.MySQLPool{MaxIdle: 1}})
+
+	// the client logged in to us waits for the retry on another backend
+	proxySide, clientSide := tcpPair(t)
+	req := &Request{Conn: proxySide, start: time.Now(), backend: NewBackend("127.0.0.1:1", 0, 0)}
+	go s.run(req)
+
+	client := mysqlproto.NewConn(clientSide)
+	connected := make(chan error, 1)
+	go func() {
+		_, err := mysqlproto.Connect(client, &mysqlproto.Login{User: "app", Password: "secret", Charset: mysqlproto.DefaultCharset})
+		connected <- err
+	}()
+
+	if req = <-s.done; !isDialError(req.err) || req.session == nil {
+		t.Fatalf("unexpected error %v", req.err)
+	}
+	req.err = nil
+	req.backend = f.backend()
+	go s.run(req)
+
+	if err := <-connected; err != nil {
+		t.Fatal(err)
+	}
+	if got := query(t, client, "SELECT v FROM t"); got != "writer" {
+		t.Fatalf("got %s", got)
+	}
+	client.WriteCommand([]byte{mysqlproto.COM_QUIT})
+	if req := <-s.done; req.err != nil || req.copyErr != nil {
+		t.Fatal(req.err, req.copyErr)
+	}
+
+	// a backend closing the connection before the handshake
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+
+	if _, err := startSession(t, s, NewBackend(l.Addr().String(), 0, 0), nil, "secret"); err == nil {
+		t.Fatal("logged in without a backend")
+	}
+	if req := <-s.done; req.err == nil || isDialError(req.err) {
+		t.Fatalf("unexpected error %v", req.err)
+	}
+}
+
+func TestMySQLPoolReadded(t *testing.T) {
+	f := newFakeMySQL(t, "writer")
+	defer f.listener.Close()
+
+	s := newMySQLScheduler(&config.MySQLConfig{Pool: config.MySQLPool{MaxIdle: 1, MaxConnections: 1}})
+	b := f.backend()
+
+	client, err := startSession(t, s, b, nil, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the connection in use still counts once the backend is back
+	s.mysql.closePool(b.address)
+	s.mysql.openPool(b.address)
+	if _, err := startSession(t, s, b, nil, "secret"); err == nil {
+		t.Fatal("exceeded the limit of the backend")
+	}
+	<-s.done
+
+	client.WriteCommand([]byte{mysqlproto.COM_QUIT})
+	if req := <-s.done; req.copyErr != nil {
+		t.Fatal(req.copyErr)
+	}
+
+	client, err = startSession(t, s, b, nil, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.WriteCommand([]byte{mysqlproto.COM_QUIT})
+	<-s.done
+
+	s.mysql.Lock()
+	p := s.mysql.pools[b.address]
+	s.mysql.Unlock()
+	p.Lock()
+	defer p.Unlock()
+	if p.open != 1 || len(p.idle) != 1 {
+		t.Fatalf("%d connections open, %d idle", p.open, len(p.idle))
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package native
+
+import (
+	"fmt"
+	"github.com/zhgwenming/gbalancer/mysqlproto"
+	"net"
+	"sync"
+	"time"
+)
+
+// backendConn is an authenticated connection to a backend, it's counted
+// by the pool of the backend until it's closed
+type backendConn struct {
+	*mysqlproto.Conn
+	pool  *mysqlPool // nil if there're no limits
+	key   string     // the login it's authenticated with
+	since time.Time  // idle since
+	once  sync.Once
+}
+
+func (c *backendConn) Close() error {
+	c.once.Do(func() {
+		if c.pool != nil {
+			c.pool.release()
+		}
+	})
+	return c.Conn.Close()
+}
+
+// mysqlPool keeps the idle connections of a backend for the next sessions
+// with the same login, and limits the connections to the backend
+type mysqlPool struct {
+	sync.Mutex
+	idle   []*backendConn
+	open   int // idle and in use
+	closed bool
+
+	maxIdle int
+	maxOpen int
+	timeout time.Duration
+}
+
+// get returns an idle connection of the login, the stale ones are closed
+func (p *mysqlPool) get(key string) *backendConn {
+	var conn *backendConn
+	var stale []*backendConn
+
+	p.Lock()
+	idle := p.idle[:0]
+	for _, c := range p.idle {
+		if time.Since(c.since) > p.timeout {
+			stale = append(stale, c)
+		} else {
+			idle = append(idle, c)
+		}
+	}
+	p.idle = idle
+
+	// the most recent one is the most likely alive
+	for i := len(p.idle) - 1; i >= 0; i-- {
+		if p.idle[i].key == key {
+			conn = p.idle[i]
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			break
+		}
+	}
+	p.Unlock()
+
+	for _, c := range stale {
+		c.Close()
+	}
+	return conn
+}
+
+// reserve counts a new connection, the idle ones of the other logins give
+// way to it once the backend is at the limit
+func (p *mysqlPool) reserve() error {
+	for {
+		p.Lock()
+		if p.maxOpen <= 0 || p.open < p.maxOpen {
+			p.open++
+			p.Unlock()
+			return nil
+		}
+
+		if len(p.idle) == 0 {
+			p.Unlock()
+			return fmt.Errorf("reached the limit of %d connections", p.maxOpen)
+		}
+		victim := p.idle[0]
+		p.idle = p.idle[1:]
+		p.Unlock()
+
+		victim.Close()
+	}
+}
+
+func (p *mysqlPool) release() {
+	p.Lock()
+	p.open--
+	p.Unlock()
+}
+
+// put keeps the connection, false if it's not wanted
+func (p *mysqlPool) put(c *backendConn) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.closed || len(p.idle) >= p.maxIdle {
+		return false
+	}
+	c.since = time.Now()
+	p.idle = append(p.idle, c)
+	return true
+}
+
+// close drops the idle connections, the ones in use are closed once the
+// sessions finished
+func (p *mysqlPool) close() {
+	p.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.Unlock()
+
+	for _, c := range idle {
+		c.Close()
+	}
+}
+
+// pool returns the pool of the backend, nil if neither pooling nor the
+// limit is enabled
+func (m *mysqlSettings) pool(addr string) *mysqlPool {
+	if m.maxIdle <= 0 && m.maxOpen <= 0 {
+		return nil
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	p, ok := m.pools[addr]
+	if !ok {
+		p = &mysqlPool{maxIdle: m.maxIdle, maxOpen: m.maxOpen, timeout: m.idleTimeout}
+		m.pools[addr] = p
+	}
+	return p
+}
+
+// closePool drops the idle connections of a backend taken down, the pool
+// is kept to count the ones in use until the backend comes back
+func (m *mysqlSettings) closePool(addr string) {
+	m.Lock()
+	p, ok := m.pools[addr]
+	m.Unlock()
+
+	if ok {
+		p.close()
+	}
+}
+
+// openPool keeps the connections of a backend brought back again
+func (m *mysqlSettings) openPool(addr string) {
+	m.Lock()
+	p, ok := m.pools[addr]
+	m.Unlock()
+
+	if ok {
+		p.Lock()
+		p.closed = false
+		p.Unlock()
+	}
+}
+
+func (m *mysqlSettings) backendVersion() string {
+	m.Lock()
+	defer m.Unlock()
+	return m.serverVersion
+}
+
+func (m *mysqlSettings) setBackendVersion(version string) {
+	m.Lock()
+	m.serverVersion = version
+	m.Unlock()
+}
+
+func poolKey(login *mysqlproto.Login) string {
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%d", login.User, login.Database, login.Charset, login.Capabilities)
+}
+
+// clientHost is the host of the client, the one of the PROXY header if the
+// listener accepts one
+func clientHost(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// acquire returns a connection to the backend authenticated as the client,
+// a pooled one if there is
+func (m *mysqlSession) acquire(b *Backend) (*backendConn, error) {
+	key := poolKey(m.login)
+	// the PROXY header went out with the dial, so the connection only
+	// serves the clients of the host it names
+	if b.proxy > 0 {
+		key += "\x00" + clientHost(m.req.Conn)
+	}
+	pool := m.settings.pool(b.address)
+
+	if pool != nil {
+		for {
+			c := pool.get(key)
+			if c == nil {
+				break
+			}
+
+			// the backend might have closed it meanwhile
+			if _, known, err := c.ping(); err == nil && known {
+				return c, nil
+			}
+			c.Close()
+		}
+
+		if err := pool.reserve(); err != nil {
+			return nil, &mysqlproto.Error{Code: mysqlproto.ER_CON_COUNT_ERROR, State: "08004",
+				Message: fmt.Sprintf("Too many connections to %s", b.address)}
+		}
+	}
+
+	// the access log tells the mode of the writer
+	tunnel := m.req.tunnel
+	conn, err := b.ForwarderNewConnection(m.req)
+	if b != m.req.backend {
+		m.req.tunnel = tunnel
+	}
+	if err != nil {
+		if pool != nil {
+			pool.release()
+		}
+		return nil, err
+	}
+
+	c := &backendConn{Conn: mysqlproto.NewConn(conn), pool: pool, key: key}
+	h, err := mysqlproto.Connect(c.Conn, m.login)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	m.settings.setBackendVersion(h.Version)
+	return c, nil
+}
+
+func (c *backendConn) ping() (uint16, bool, error) {
+	if err := c.WriteCommand([]byte{mysqlproto.COM_PING}); err != nil {
+		return 0, false, err
+	}
+	return c.ReadSimple(nil)
+}
+
+// recycle resets the connection and gives it back to the pool, it's closed
+// if the session left it in a state we can't reset
+func (m *mysqlSession) recycle(c *backendConn, clean bool) {
+	if c.pool == nil || m.settings.maxIdle <= 0 || !clean || m.pinned {
+		c.Close()
+		return
+	}
+
+	err := c.WriteCommand([]byte{mysqlproto.COM_RESET_CONNECTION})
+	if err == nil {
+		var known bool
+		if _, known, err = c.ReadSimple(nil); err == nil && !known {
+			err = fmt.Errorf("COM_RESET_CONNECTION failed")
+		}
+	}
+
+	// the reset keeps the current database
+	if err == nil && m.changedDB {
+		if m.login.Database == "" {
+			err = fmt.Errorf("the database got changed")
+		} else if err = c.WriteCommand(append([]byte{mysqlproto.COM_INIT_DB}, m.login.Database...)); err == nil {
+			var known bool
+			if _, known, err = c.ReadSimple(nil); err == nil && !known {
+				err = fmt.Errorf("failed to switch back to %s", m.login.Database)
+			}
+		}
+	}
+
+	if err != nil {
+		log.Debugf("mysql: not pooling the connection: %s", err)
+		c.Close()
+		return
+	}
+
+	if !c.pool.put(c) {
+		c.Close()
+	}
+}
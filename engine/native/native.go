@@ -5,73 +5,242 @@
 package native
 
 import (
+	"crypto/tls"
 	"flag"
+	"fmt"
 	"github.com/zhgwenming/gbalancer/config"
+	nestor "github.com/zhgwenming/gbalancer/daemon"
 	logger "github.com/zhgwenming/gbalancer/log"
+	"github.com/zhgwenming/gbalancer/proxyproto"
 	"net"
 	"runtime/debug"
 	"sync"
+	"syscall"
+	"time"
 )
 
 var (
-	log        = logger.NewLogger()
+	log        = logger.New("native")
 	tunnels    = flag.Uint("tunnels", 0, "number of tunnels per server")
 	streamPort = flag.String("streamport", "6900", "port of the remote stream server")
 	failover   = flag.Bool("failover", false, "whether to enable failover mode for scheduling")
 	shuffle    = flag.Bool("shuffle", true, "whether to enable shuffle for server list")
+	accessFile = flag.String("accesslog", "", "file to log every forwarded connection, reopened on SIGUSR1")
+	accessJSON = flag.Bool("accessjson", false, "write the access log in json")
 )
 
-func Serve(settings *config.Configuration, wgroup *sync.WaitGroup, done chan struct{}, status chan map[string]int) {
+// Serve starts forwarding the connections of the listeners, it returns an
+// error if the settings of the engine are invalid
+func Serve(settings *config.Configuration, wgroup *sync.WaitGroup, done chan struct{}, status chan map[string]int) (*Scheduler, error) {
 	job := make(chan *Request)
 
 	// start the scheduler
 	sch := NewScheduler(*failover, *tunnels)
-	go sch.EventLoop(job, status)
+	versions, err := settings.ProxyVersions()
+	if err != nil {
+		return nil, err
+	}
+	sch.sendProxy = versions
+
+	if settings.MySQLAware() {
+		if sch.mysql, err = newMySQLSettings(&settings.MySQL); err != nil {
+			return nil, err
+		}
+	}
+	if settings.Rejects() {
+		sch.mysqlErrors = newMySQLErrors(&settings.MySQL)
+	}
+
+	if sch.outlier, err = newOutlierSettings(&settings.Outlier); err != nil {
+		return nil, err
+	}
+
+	if sch.backendTLS, err = backendTLS(settings, *tunnels); err != nil {
+		return nil, err
+	}
 
 	listenAddrs, err := settings.GetListenAddrs()
+	if err != nil {
+		return nil, err
+	}
+
+	// the TLS settings of the listeners
+	tlsConfigs := make(map[*config.ListenAddr]*tls.Config)
+	for i, listenAddr := range listenAddrs {
+		if listenAddr.TLS {
+			if tlsConfigs[&listenAddrs[i]], err = settings.ListenerTLS(listenAddr.String()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// socket activated listeners take the place of the configured ones
+	activated, err := nestor.Activated()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	for _, listenAddr := range listenAddrs {
-		listener, err := listenAddr.Listen()
+	served := make([]listening, 0, len(listenAddrs))
+	if activated != nil {
+		matched := make(map[*config.ListenAddr]bool)
+		for _, listener := range activated {
+			addr := matchListenAddr(listener, listenAddrs)
+			matched[addr] = true
+			served = append(served, listening{listener, addr})
+		}
+
+		// never serve plain what's configured to be TLS
+		for i := range listenAddrs {
+			if listenAddrs[i].TLS && !matched[&listenAddrs[i]] {
+				return nil, fmt.Errorf("TLS listener %s isn't among the activated ones", listenAddrs[i].String())
+			}
+		}
+	}
+
+	if *accessFile != "" {
+		a, err := newAccessLog(*accessFile, *accessJSON)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sch.accessLog = a
+		nestor.HandleSignal(syscall.SIGUSR1, a.Reopen)
+	}
+	go sch.EventLoop(job, status)
+
+	if activated == nil {
+		for i := range listenAddrs {
+			listener, err := listenAddrs[i].Listen()
+			if err != nil {
+				log.Fatal(err)
+			}
+			served = append(served, listening{listener, &listenAddrs[i]})
+		}
+	}
+
+	for _, l := range served {
+		listener := l.Listener
 
 		// close the listener makes the unix socket file got removed
 		wgroup.Add(1)
-		go func() {
+		go func(listener net.Listener) {
 			<-done
 			listener.Close()
-		}()
+		}(listener)
 
-		if err != nil {
-			log.Fatal(err)
+		addr := listener.Addr()
+		name := addr.Network() + "://" + addr.String()
+		if l.addr != nil && l.addr.Proxy {
+			log.Printf("accepting PROXY protocol on %s", name)
+			listener = proxyproto.NewListener(listener)
+		}
+
+		// the PROXY header comes before the TLS handshake
+		if config, ok := tlsConfigs[l.addr]; ok {
+			log.Printf("terminating TLS on %s", name)
+			listener = tls.NewListener(listener, config)
+		}
+
+		// the listener only forwards to the backends of its role
+		role := 0
+		if l.addr != nil {
+			switch l.addr.Role {
+			case "primary":
+				role = FlagPrimary
+			case "replica":
+				role = FlagReplica
+			}
 		}
 
-		// tcp/unix listener
-		go func(listen config.ListenAddr) {
-
-			for {
-				if conn, err := listener.Accept(); err == nil {
-					//log.Println("main: got a connection")
-					req := &Request{Conn: conn}
-					job <- req
-				} else {
-					if neterr, ok := err.(net.Error); ok && neterr.Temporary() {
-						log.Printf("%s\n", err)
-					} else {
-						// we should got a errClosing
-						log.Printf("stop listening for %s:%s\n", listen.Net, listen.Addr)
-						wgroup.Done()
-						return
-					}
+		go accept(listener, l.addr != nil && l.addr.Proxy, role, job, wgroup)
+	}
+
+	return sch, nil
+}
+
+// backendTLS returns the TLS settings of the backends, nil if they're plain
+func backendTLS(settings *config.Configuration, tunnels uint) (map[string]*tls.Config, error) {
+	if len(settings.BackendTLS) == 0 {
+		return nil, nil
+	}
+
+	// the streams of a tunnel can't be wrapped in TLS
+	if tunnels > 0 {
+		return nil, fmt.Errorf("BackendTLS can't be used with -tunnels")
+	}
+	return settings.BackendTLSConfigs()
+}
+
+// accept hands the connections over to the scheduler, the PROXY header is
+// read before, a client sending a bad one or none at all is never put on
+// a backend
+func accept(listener net.Listener, proxied bool, role int, job chan<- *Request, wgroup *sync.WaitGroup) {
+	addr := listener.Addr()
+	name := addr.Network() + "://" + addr.String()
+
+	for {
+		if conn, err := listener.Accept(); err == nil {
+			//log.Println("main: got a connection")
+			req := &Request{Conn: conn, role: role, listener: name, start: time.Now()}
+			if !proxied {
+				job <- req
+				continue
+			}
+
+			go func(req *Request) {
+				if err := readProxyHeader(req.Conn); err != nil {
+					log.Warnf("%s: dropped %s: %s\n", name, req.Conn.RemoteAddr(), err)
+					req.Conn.Close()
+					return
 				}
+				job <- req
+			}(req)
+		} else {
+			if neterr, ok := err.(net.Error); ok && neterr.Temporary() {
+				log.Errorf("%s\n", err)
+			} else {
+				// we should got a errClosing
+				log.Printf("stop listening for %s:%s\n", addr.Network(), addr.String())
+				wgroup.Done()
+				return
 			}
-		}(listenAddr)
+		}
+	}
+}
+
+// readProxyHeader waits for the PROXY header of the upstream balancer, it
+// comes before the TLS handshake
+func readProxyHeader(conn net.Conn) error {
+	if c, ok := conn.(*tls.Conn); ok {
+		conn = c.NetConn()
+	}
+
+	if c, ok := conn.(*proxyproto.Conn); ok {
+		_, err := c.Header()
+		return err
+	}
+	return nil
+}
+
+// listening is a listener along with the options it got configured with,
+// addr is nil for an activated listener matching none of the configured
+type listening struct {
+	net.Listener
+	addr *config.ListenAddr
+}
+
+// matchListenAddr finds the configured options of an activated listener
+func matchListenAddr(listener net.Listener, listenAddrs []config.ListenAddr) *config.ListenAddr {
+	for i := range listenAddrs {
+		if listenAddrs[i].Matches(listener.Addr()) {
+			return &listenAddrs[i]
+		}
 	}
+	log.Warnf("activated listener %s isn't configured, serving it plain", listener.Addr())
+	return nil
 }
 
 func RecoverReport() {
 	if p := recover(); p != nil {
-		log.Printf("%s\nbacktrace:\n%s", p, debug.Stack())
+		log.Errorf("%s\nbacktrace:\n%s", p, debug.Stack())
 	}
 }
@@ -0,0 +1,95 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package native
+
+import (
+	"github.com/zhgwenming/gbalancer/config"
+	"github.com/zhgwenming/gbalancer/proxyproto"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcceptProxy(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := make(chan *Request)
+	wgroup := &sync.WaitGroup{}
+	wgroup.Add(1)
+	go accept(proxyproto.NewListener(l), true, FlagPrimary, job, wgroup)
+	defer wgroup.Wait()
+	defer l.Close()
+
+	// a bad header never gets to the scheduler
+	bad, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bad.Close()
+	bad.Write([]byte("GET / HTTP/1.0\r\n\r\n"))
+
+	select {
+	case req := <-job:
+		t.Fatalf("dispatched the client with a bad header from %s", req.Conn.RemoteAddr())
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	bad.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := bad.Read(make([]byte, 1)); err == nil {
+		t.Error("expected the client with a bad header to be closed")
+	}
+
+	// the silent one neither blocks the others
+	silent, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+
+	good, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer good.Close()
+
+	src := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 40000}
+	dst := &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 3306}
+	if _, err := proxyproto.NewHeader(1, src, dst).WriteTo(good); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case req := <-job:
+		if req.Conn.RemoteAddr().String() != src.String() || req.role != FlagPrimary {
+			t.Errorf("unexpected request from %s with role %d", req.Conn.RemoteAddr(), req.role)
+		}
+		req.Conn.Close()
+	case <-time.After(2 * time.Second):
+		t.Fatal("the client with a good header isn't dispatched")
+	}
+}
+
+func TestBackendTLSTunnels(t *testing.T) {
+	settings := &config.Configuration{
+		BackendTLS: map[string]config.BackendTLSConfig{"*": {InsecureSkipVerify: true}},
+	}
+
+	if _, err := backendTLS(settings, 1); err == nil {
+		t.Error("expected BackendTLS to be rejected with the tunnels")
+	}
+	if configs, err := backendTLS(settings, 0); err != nil || configs["*"] == nil {
+		t.Errorf("unexpected result %v %v", configs, err)
+	}
+
+	// the tunnels are fine without BackendTLS
+	settings.BackendTLS = nil
+	if configs, err := backendTLS(settings, 1); err != nil || configs != nil {
+		t.Errorf("unexpected result %v %v", configs, err)
+	}
+}
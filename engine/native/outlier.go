@@ -0,0 +1,210 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package native
+
+import (
+	"container/heap"
+	"fmt"
+	"github.com/zhgwenming/gbalancer/config"
+	"time"
+)
+
+type outlierSettings struct {
+	consecutive  uint
+	ratio        float64
+	minConns     uint
+	interval     time.Duration
+	resetWithin  time.Duration // 0 if the resets aren't counted
+	baseEjection time.Duration
+	maxEjection  time.Duration
+	maxPercent   int
+	tick         <-chan time.Time
+}
+
+// the live traffic of a backend
+type outlierState struct {
+	consecutive  uint // failed connections in a row
+	conns        uint // connections of the current interval
+	failed       uint
+	since        time.Time // start of the interval
+	ejections    uint      // ejections in a row
+	ejectedUntil time.Time // zero unless ejected
+	returned     time.Time // end of the last ejection
+}
+
+// newOutlierSettings returns nil unless the outlier detection is enabled
+func newOutlierSettings(c *config.OutlierConfig) (*outlierSettings, error) {
+	if c.ConsecutiveFailures <= 0 && c.ErrorRatio <= 0 {
+		return nil, nil
+	}
+	if c.ErrorRatio > 1 {
+		return nil, fmt.Errorf("Outlier.ErrorRatio %g is above 1", c.ErrorRatio)
+	}
+
+	o := &outlierSettings{
+		ratio:        c.ErrorRatio,
+		minConns:     OutlierMinConnections,
+		interval:     OutlierInterval,
+		baseEjection: OutlierBaseEjection,
+		maxEjection:  OutlierMaxEjection,
+		maxPercent:   OutlierMaxEjectedPercent,
+	}
+	if c.ConsecutiveFailures > 0 {
+		o.consecutive = uint(c.ConsecutiveFailures)
+	}
+	if c.MinConnections > 0 {
+		o.minConns = uint(c.MinConnections)
+	}
+	if c.MaxEjectedPercent != 0 {
+		if c.MaxEjectedPercent < 0 || c.MaxEjectedPercent > 100 {
+			return nil, fmt.Errorf("Outlier.MaxEjectedPercent %d isn't within 0-100", c.MaxEjectedPercent)
+		}
+		o.maxPercent = c.MaxEjectedPercent
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		d     *time.Duration
+	}{
+		{"Interval", c.Interval, &o.interval},
+		{"ResetWithin", c.ResetWithin, &o.resetWithin},
+		{"BaseEjection", c.BaseEjection, &o.baseEjection},
+		{"MaxEjection", c.MaxEjection, &o.maxEjection},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid Outlier.%s %s", d.name, d.value)
+		}
+		*d.d = v
+	}
+
+	if o.maxEjection < o.baseEjection {
+		o.maxEjection = o.baseEjection
+	}
+	o.tick = time.NewTicker(OutlierTick).C
+	return o, nil
+}
+
+// ejected tells whether the backend is kept out for its failures
+func (b *Backend) ejected() bool {
+	return !b.outlier.ejectedUntil.IsZero()
+}
+
+// outlierTick is nil if the outlier detection is disabled
+func (s *Scheduler) outlierTick() <-chan time.Time {
+	if s.outlier == nil {
+		return nil
+	}
+	return s.outlier.tick
+}
+
+// observe records the result of a connection to the backend and ejects it
+// once it failed too much
+func (s *Scheduler) observe(b *Backend, failed bool, now time.Time) {
+	o := s.outlier
+	if o == nil || s.backends[b.address] != b || b.ejected() {
+		return
+	}
+
+	state := &b.outlier
+	if now.Sub(state.since) >= o.interval {
+		state.conns, state.failed, state.since = 0, 0, now
+	}
+
+	state.conns++
+	if !failed {
+		state.consecutive = 0
+		return
+	}
+	state.consecutive++
+	state.failed++
+
+	switch {
+	case o.consecutive > 0 && state.consecutive >= o.consecutive:
+		s.eject(b, fmt.Sprintf("%d connections failed in a row", state.consecutive), now)
+	case o.ratio > 0 && state.conns >= o.minConns && float64(state.failed) >= o.ratio*float64(state.conns):
+		s.eject(b, fmt.Sprintf("%d of %d connections failed", state.failed, state.conns), now)
+	}
+}
+
+// eject keeps the backend out of the heap for a period growing with the
+// ejections in a row, unless too many backends are out already
+func (s *Scheduler) eject(b *Backend, reason string, now time.Time) {
+	o := s.outlier
+
+	ejected := 0
+	for _, backend := range s.backends {
+		if backend.ejected() {
+			ejected++
+		}
+	}
+	if (ejected+1)*100 > o.maxPercent*len(s.backends) {
+		log.Warnf("balancer: not ejecting %s with %s, %d of %d backends are ejected already\n",
+			b.address, reason, ejected, len(s.backends))
+		return
+	}
+
+	state := &b.outlier
+	// a backend which behaved for a while starts over
+	if state.ejections > 0 && now.Sub(state.returned) > o.maxEjection {
+		state.ejections = 0
+	}
+	period := o.baseEjection
+	for i := uint(0); i < state.ejections && period < o.maxEjection; i++ {
+		period *= 2
+	}
+	if period > o.maxEjection {
+		period = o.maxEjection
+	}
+
+	state.ejections++
+	state.ejectedUntil = now.Add(period)
+	state.consecutive, state.conns, state.failed = 0, 0, 0
+	if b.index != -1 {
+		heap.Remove(&s.pool, b.index)
+	}
+	log.Warnf("balancer: ejecting %s for %s, %s\n", b.address, period, reason)
+}
+
+func (s *Scheduler) ejections() map[string]time.Time {
+	ejected := make(map[string]time.Time)
+	for addr, b := range s.backends {
+		if b.ejected() {
+			ejected[addr] = b.outlier.ejectedUntil
+		}
+	}
+	return ejected
+}
+
+// Ejections returns until when the ejected backends are kept out, safe to
+// be called outside of the scheduler goroutine
+func (s *Scheduler) Ejections() map[string]time.Time {
+	reply := make(chan map[string]time.Time, 1)
+	s.ejectedChan <- reply
+	return <-reply
+}
+
+// returnEjected brings back the backends whose ejection is over
+func (s *Scheduler) returnEjected(now time.Time) {
+	for _, b := range s.backends {
+		state := &b.outlier
+		if !b.ejected() || now.Before(state.ejectedUntil) {
+			continue
+		}
+
+		state.ejectedUntil = time.Time{}
+		state.returned = now
+		log.Printf("balancer: %s is back from the ejection\n", b.address)
+		if b.index == -1 && !b.draining {
+			heap.Push(&s.pool, b)
+		}
+	}
+	s.drainPending()
+}
@@ -18,7 +18,8 @@ func (p Pool) Less(i, j int) bool {
 	if p.leastweight {
 		return p.backends[i].weight < p.backends[j].weight
 	} else {
-		return p.backends[i].ongoing < p.backends[j].ongoing
+		// the least connections relative to the weight
+		return p.backends[i].ongoing*p.backends[j].capacity < p.backends[j].ongoing*p.backends[i].capacity
 	}
 }
 
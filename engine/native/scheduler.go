@@ -6,17 +6,38 @@ package native
 
 import (
 	"container/heap"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	//splice "github.com/creack/go-splice"
+	"github.com/zhgwenming/gbalancer/config"
+	nestor "github.com/zhgwenming/gbalancer/daemon"
 	"github.com/zhgwenming/gbalancer/utils"
 	"io"
 	"net"
 	"sort"
+	"syscall"
+	"time"
 )
 
 type Request struct {
 	Conn    net.Conn
 	backend *Backend
 	err     error
+	reader  *Backend      // for the reads in the mysql mode
+	role    int           // the backend role of the listener, 0 for any
+	session *mysqlSession // the mysql client logged in already on retry
+
+	// for the access log
+	listener string
+	start    time.Time
+	dialTime time.Duration
+	tunnel   bool
+	bytesIn  int64 // from the client
+	bytesOut int64 // to the client
+	retries  int
+	copyErr  error
+	reset    bool // the backend reset it right after the dial
 }
 
 type Forwarder struct {
@@ -34,6 +55,16 @@ type Scheduler struct {
 	tunnels       uint
 	newTunnelChan chan *spdySession
 	spdyFailChan  chan *spdySession
+	statsChan     chan chan map[string]uint
+	ejectedChan   chan chan map[string]time.Time
+	watchdog      <-chan time.Time       // nil if the systemd watchdog is disabled
+	accessLog     *accessLog             // nil if the access log is disabled
+	sendProxy     map[string]int         // PROXY protocol version of the backends
+	mysql         *mysqlSettings         // nil unless the mysql protocol aware mode is enabled
+	mysqlErrors   *mysqlErrors           // nil to close the rejected clients silently
+	backendTLS    map[string]*tls.Config // nil if the backends are plain
+	outlier       *outlierSettings       // nil unless the outlier detection is enabled
+	checked       bool                   // got the first backends from the wrangler
 }
 
 // it's a leastweight heap if we do persistent scheduling
@@ -47,7 +78,15 @@ func NewScheduler(lw bool, tunnels uint) *Scheduler {
 	readyChan := make(chan *spdySession, MaxBackends)
 	failChan := make(chan *spdySession, MaxBackends)
 
-	scheduler := &Scheduler{pool, 0, backends, done, pending, tunnels, readyChan, failChan}
+	statsChan := make(chan chan map[string]uint)
+	ejectedChan := make(chan chan map[string]time.Time)
+
+	var watchdog <-chan time.Time
+	if interval := nestor.WatchdogInterval(); interval > 0 {
+		watchdog = time.NewTicker(interval).C
+	}
+
+	scheduler := &Scheduler{pool, 0, backends, done, pending, tunnels, readyChan, failChan, statsChan, ejectedChan, watchdog, nil, nil, nil, nil, nil, nil, false}
 	return scheduler
 }
 
@@ -72,30 +111,32 @@ func (s *Scheduler) Schedule(job chan *Request, status <-chan map[string]int) {
 			//log.Println("finishing a connection")
 			s.finish(back)
 		case backends := <-status:
-			if len(backends) == 0 {
-				log.Printf("balancer: got empty backends list")
-			}
+			s.checked = true
 
-			for addr, b := range s.backends {
-				if _, ok := backends[addr]; !ok {
-					// not exist in the active backend list
-					s.RemoveBackend(addr)
-				} else {
-					delete(backends, addr)
+			// only the changed backends come, FlagDown for the gone ones
+			var addrs []string
+			for addr, flag := range backends {
+				b, ok := s.backends[addr]
+				switch {
+				case flag == FlagDown:
+					if ok {
+						s.RemoveBackend(addr)
+					}
+				case !ok:
+					// the NEW active backends
+					addrs = append(addrs, addr)
+				default:
+					if b.status != flag {
+						s.updateStatus(b, flag)
+					}
 					// push back backend with error in run()
-					if b.index == -1 {
+					if b.index == -1 && !b.draining && !b.ejected() {
 						log.Printf("balancer: bring back %s to up\n", b.address)
-						heap.Push(&s.pool, s.backends[addr])
+						heap.Push(&s.pool, b)
 					}
 				}
 			}
 
-			// the NEW active backends
-			var addrs []string
-			for addr := range backends {
-				addrs = append(addrs, addr)
-			}
-
 			// 1. shuffle them first if needed
 			if *shuffle {
 				addrs = utils.Shuffle(addrs)
@@ -112,6 +153,9 @@ func (s *Scheduler) Schedule(job chan *Request, status <-chan map[string]int) {
 					weight = s.nextBackendSequence()
 				}
 				b := NewBackend(addr, s.tunnels, weight)
+				b.proxy = s.proxyVersion(addr)
+				b.setStatus(backends[addr])
+				b.tls = config.BackendTLS(s.backendTLS, addr)
 				//b.failChan = &s.spdyFailChan
 				b.FailChan(s.spdyFailChan)
 				if s.tunnels > 0 {
@@ -138,50 +182,179 @@ func (s *Scheduler) Schedule(job chan *Request, status <-chan map[string]int) {
 			if _, ok := s.backends[b.address]; !ok {
 				// a new backend, add it to the hash
 				s.AddBackend(b)
-				// drain the pending list
-				if len(s.pending) > 0 && len(s.pool.backends) > 0 {
-					for _, p := range s.pending {
-						s.dispatch(p)
-					}
-					s.pending = s.pending[0:0]
-				}
+				s.drainPending()
 			}
 		case j := <-job:
 			s.dispatch(j)
+		case reply := <-s.statsChan:
+			reply <- s.activeConnections()
+		case reply := <-s.ejectedChan:
+			reply <- s.ejections()
+		case now := <-s.outlierTick():
+			s.returnEjected(now)
+		case <-s.watchdog:
+			// a wedged event loop stops pinging and gets restarted
+			if err := nestor.Notify("WATCHDOG=1"); err != nil {
+				log.Warnf("sd_notify: %s\n", err)
+			}
 		}
 
 	}
 }
 
+// drainPending dispatches the pending list once a backend is available
+func (s *Scheduler) drainPending() {
+	if len(s.pending) > 0 && len(s.pool.backends) > 0 {
+		for _, p := range s.pending {
+			s.dispatch(p)
+		}
+		s.pending = s.pending[0:0]
+	}
+}
+
 // dispatch or add to pending list
 func (s *Scheduler) dispatch(req *Request) {
-	// add to pending list
 	if len(s.pool.backends) == 0 {
+		log.Warnf("No backend available\n")
+
+		// the mysql clients rather get an error than wait, but not for
+		// the first check to pass on start
+		if s.mysqlErrors != nil && s.checked {
+			s.logAccess(req, fmt.Errorf("no backend available"))
+			s.reject(req, false)
+			return
+		}
+
+		// add to pending list
 		s.pending = append(s.pending, req)
-		log.Printf("No backend available\n")
 		return
 	}
 	//log.Println("Got a connection")
 
-	b := heap.Pop(&s.pool).(*Backend)
+	var b *Backend
+	role := req.role
+	split := s.mysql != nil && s.mysql.split
+	if split && role == 0 {
+		// the replicas rather serve the reads than the primary
+		b = s.pick(FlagReplica)
+	}
+	if b == nil {
+		b = s.pick(role)
+	}
+	if b == nil && role == FlagReplica {
+		// the primary serves the reads once no replica is in sync
+		b = s.pick(FlagPrimary)
+	}
+	if b == nil {
+		log.Warnf("No %s backend available for %s\n", roleName(role), req.listener)
+		s.logAccess(req, fmt.Errorf("no %s backend available", roleName(role)))
+		s.reject(req, false)
+		return
+	}
+
 	if b.ongoing >= MaxForwardersPerBackend {
-		heap.Push(&s.pool, b)
-		req.Conn.Close()
-		log.Printf("all backend forwarders exceed %d\n", MaxForwardersPerBackend)
+		log.Warnf("all backend forwarders exceed %d\n", MaxForwardersPerBackend)
+		s.logAccess(req, fmt.Errorf("all backend forwarders exceed %d", MaxForwardersPerBackend))
+		s.reject(req, true)
 		return
 	}
 
 	b.ongoing++
 
-	heap.Push(&s.pool, b)
+	heap.Fix(&s.pool, b.index)
 	b.SpdyCheckStreamId(s.newTunnelChan)
 	req.backend = b
+
+	// the least loaded backend serves the reads
+	if split {
+		req.reader = b
+		req.backend = s.writer()
+		if req.backend != b {
+			req.backend.ongoing++
+			heap.Fix(&s.pool, req.backend.index)
+		}
+	}
 	go s.run(req)
 }
 
+// reject closes the client, the mysql ones get the error first if it's
+// configured. The client logged in to us already is always told.
+func (s *Scheduler) reject(req *Request, busy bool) {
+	switch {
+	case req.session != nil:
+		go req.session.fail()
+	case s.mysqlErrors == nil:
+		req.Conn.Close()
+	case busy:
+		go rejectMySQL(req.Conn, s.mysqlErrors.version, s.mysqlErrors.busy)
+	default:
+		go rejectMySQL(req.Conn, s.mysqlErrors.version, s.mysqlErrors.noBackend)
+	}
+}
+
+// pick returns the first backend of the role in the heap order, any
+// backend for role 0 and nil if there's none of the role
+func (s *Scheduler) pick(role int) *Backend {
+	if role == 0 {
+		return s.pool.backends[0]
+	}
+
+	var picked *Backend
+	for i, b := range s.pool.backends {
+		if b.role == role && (picked == nil || s.pool.Less(i, picked.index)) {
+			picked = b
+		}
+	}
+	return picked
+}
+
+// writer is the primary if the check tells, otherwise the backend with the
+// lowest address, so all the balancers agree on it without talking to
+// each other
+func (s *Scheduler) writer() *Backend {
+	if primary := s.pick(FlagPrimary); primary != nil {
+		return primary
+	}
+
+	var writer *Backend
+	for _, b := range s.pool.backends {
+		if writer == nil || b.address < writer.address {
+			writer = b
+		}
+	}
+	return writer
+}
+
+// updateStatus applies the new state of a backend to the heap
+func (s *Scheduler) updateStatus(b *Backend, flag int) {
+	b.setStatus(flag)
+	log.Printf("balancer: %s is now a %s backend with weight %d\n", b.address, roleName(b.role), b.capacity)
+
+	if b.index == -1 {
+		return
+	}
+	if b.draining {
+		log.Printf("balancer: draining %s\n", b.address)
+		heap.Remove(&s.pool, b.index)
+	} else {
+		heap.Fix(&s.pool, b.index)
+	}
+}
+
+func roleName(role int) string {
+	switch role {
+	case FlagPrimary:
+		return "primary"
+	case FlagReplica:
+		return "replica"
+	}
+	return "healthy"
+}
+
 type copyRet struct {
-	bytes int64
-	err   error
+	bytes    int64
+	err      error
+	upstream bool
 }
 
 //func spliceCopy(dst io.Writer, src io.Reader, c chan *copyRet) {
@@ -189,7 +362,7 @@ type copyRet struct {
 //	c <- &copyRet{n, err}
 //}
 
-func sockCopy(dst io.WriteCloser, src io.Reader, c chan *copyRet) {
+func sockCopy(dst io.WriteCloser, src io.Reader, upstream bool, c chan *copyRet) {
 	n, err := io.Copy(dst, src)
 	//log.Printf("sent %d bytes to server", n)
 
@@ -201,29 +374,52 @@ func sockCopy(dst io.WriteCloser, src io.Reader, c chan *copyRet) {
 	// Close the upstream connection as Deadline
 	// not yet supported by spdystream by now
 	dst.Close()
-	c <- &copyRet{n, err}
+	c <- &copyRet{n, err, upstream}
 }
 
 func (s *Scheduler) run(req *Request) {
+	if s.mysql != nil {
+		s.runMySQL(req)
+		return
+	}
+
 	// do the actuall work
+	dialStart := time.Now()
 	srv, err := req.backend.ForwarderNewConnection(req)
+	req.dialTime = time.Since(dialStart)
 	if err != nil {
 		req.err = err
 		s.done <- req
 		return
 	}
+	established := time.Now()
 
 	// no need to defer close the upstream server as sockCopy will do that
 	// defer srv.Close()
 
 	c := make(chan *copyRet, 2)
 	//log.Printf("splicing socks")
-	go sockCopy(req.Conn, srv, c)
-	go sockCopy(srv, req.Conn, c)
+	go sockCopy(req.Conn, srv, false, c)
+	go sockCopy(srv, req.Conn, true, c)
 
 	for i := 0; i < 2; i++ {
-		if r := <-c; r.err != nil {
-			req.err = err
+		r := <-c
+		if r.upstream {
+			req.bytesIn = r.bytes
+		} else {
+			req.bytesOut = r.bytes
+		}
+
+		// the other direction is interrupted by the close of the first one
+		if r.err != nil && !errors.Is(r.err, net.ErrClosed) && req.copyErr == nil {
+			req.copyErr = r.err
+		}
+
+		// the splice errors don't tell which end reset the connection, so
+		// it's put on the backend when the copy from it failed
+		if !r.upstream && s.outlier != nil && s.outlier.resetWithin > 0 &&
+			time.Since(established) < s.outlier.resetWithin && errors.Is(r.err, syscall.ECONNRESET) {
+			req.reset = true
 		}
 	}
 
@@ -232,6 +428,13 @@ func (s *Scheduler) run(req *Request) {
 
 func (s *Scheduler) finish(req *Request) {
 	backend, err := req.backend, req.err
+	s.observe(backend, err != nil || req.reset, time.Now())
+
+	// the reader is picked again on retry
+	if req.reader != nil && req.reader != backend {
+		s.release(req.reader)
+	}
+	req.reader = nil
 
 	if err != nil {
 		// keep it out of the heap
@@ -241,29 +444,88 @@ func (s *Scheduler) finish(req *Request) {
 		backend.ongoing--
 
 		// retry the connection is it failed on dial
-		if e, ok := err.(*net.OpError); ok && e.Op == "dial" {
+		if isDialError(err) {
 			// detected the connection error
 			// keep it out of the heap and try to reschedule the job
-			log.Printf("%s, rescheduling request %v\n", err, req)
+			log.Warnf("%s, rescheduling request %v\n", err, req)
+			req.retries++
+			req.err = nil
 			s.dispatch(req)
+			return
 		}
+		req.Conn.Close()
 	} else {
 		if backend.index == -1 {
 			// in case the wrangler already detected error of this backend
-			// which makes this backend already removed from the heap pool
+			// which makes this backend already removed from the heap pool,
+			// or it got ejected
 			backend.ongoing--
 		} else {
 			heap.Remove(&s.pool, backend.index)
 			backend.ongoing--
 			heap.Push(&s.pool, backend)
 		}
+		err = req.copyErr
+	}
+
+	s.logAccess(req, err)
+}
+
+// isDialError tells the failed dial, it's retried on another backend
+func isDialError(err error) bool {
+	e, ok := err.(*net.OpError)
+	return ok && e.Op == "dial"
+}
+
+// release gives back a connection of the backend
+func (s *Scheduler) release(b *Backend) {
+	b.ongoing--
+	if b.index != -1 {
+		heap.Fix(&s.pool, b.index)
+	}
+}
+
+func (s *Scheduler) proxyVersion(addr string) int {
+	if version, ok := s.sendProxy[addr]; ok {
+		return version
+	}
+	return s.sendProxy["*"]
+}
+
+func (s *Scheduler) logAccess(req *Request, err error) {
+	if s.accessLog != nil {
+		s.accessLog.Log(newAccessEntry(req, err))
 	}
 }
 
+func (s *Scheduler) activeConnections() map[string]uint {
+	active := make(map[string]uint, len(s.backends))
+	for addr, b := range s.backends {
+		active[addr] = b.ongoing
+	}
+	return active
+}
+
+// ActiveConnections returns the ongoing connections of every backend,
+// safe to be called outside of the scheduler goroutine
+func (s *Scheduler) ActiveConnections() map[string]uint {
+	reply := make(chan map[string]uint, 1)
+	s.statsChan <- reply
+	return <-reply
+}
+
 func (s *Scheduler) AddBackend(b *Backend) {
 	addr := b.address
-	log.Printf("balancer: bring up %s.\n", addr)
 	s.backends[addr] = b
+	if s.mysql != nil {
+		s.mysql.openPool(addr)
+	}
+	if b.draining {
+		log.Printf("balancer: bring up %s draining.\n", addr)
+		b.index = -1
+		return
+	}
+	log.Printf("balancer: bring up %s.\n", addr)
 	heap.Push(&s.pool, b)
 }
 
@@ -275,8 +537,12 @@ func (s *Scheduler) RemoveBackend(addr string) {
 			heap.Remove(&s.pool, b.index)
 		}
 		delete(s.backends, b.address)
+
+		if s.mysql != nil {
+			s.mysql.closePool(addr)
+		}
 	} else {
-		log.Printf("balancer: %s is not up, bug might exist!", addr)
+		log.Errorf("balancer: %s is not up, bug might exist!", addr)
 	}
 
 }
@@ -0,0 +1,124 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package native
+
+import (
+	"github.com/zhgwenming/gbalancer/config"
+	"testing"
+	"time"
+)
+
+func TestPickRole(t *testing.T) {
+	s := NewScheduler(false, 0)
+	primary := NewBackend("10.0.0.2:3306", 0, 0)
+	replica1 := NewBackend("10.0.0.1:3306", 0, 0)
+	replica2 := NewBackend("10.0.0.3:3306", 0, 0)
+	primary.role, replica1.role, replica2.role = FlagPrimary, FlagReplica, FlagReplica
+	replica1.ongoing = 2
+
+	for _, b := range []*Backend{primary, replica1, replica2} {
+		s.AddBackend(b)
+	}
+
+	if b := s.pick(FlagReplica); b != replica2 {
+		t.Fatalf("picked %s for the reads", b.address)
+	}
+	if b := s.pick(FlagPrimary); b != primary {
+		t.Fatalf("picked %s as the primary", b.address)
+	}
+	if b := s.writer(); b != primary {
+		t.Fatalf("%s is the writer", b.address)
+	}
+
+	s.RemoveBackend(primary.address)
+	if b := s.pick(FlagPrimary); b != nil {
+		t.Fatalf("picked %s without a primary", b.address)
+	}
+	if b := s.writer(); b != replica1 {
+		t.Fatalf("%s is the writer without a primary", b.address)
+	}
+}
+
+func TestWeightDrain(t *testing.T) {
+	s := NewScheduler(false, 0)
+	heavy := NewBackend("10.0.0.1:80", 0, 0)
+	light := NewBackend("10.0.0.2:80", 0, 0)
+	heavy.setStatus(FlagUp | 300<<WeightShift)
+	heavy.ongoing, light.ongoing = 2, 1
+	s.AddBackend(heavy)
+	s.AddBackend(light)
+
+	// 2 of 300 is less loaded than 1 of 100
+	if b := s.pick(0); b != heavy {
+		t.Fatalf("picked %s", b.address)
+	}
+
+	s.updateStatus(heavy, FlagUp|FlagDrain)
+	if b := s.pick(0); b != light || len(s.pool.backends) != 1 {
+		t.Fatalf("picked %s while draining", b.address)
+	}
+
+	drained := NewBackend("10.0.0.3:80", 0, 0)
+	drained.setStatus(FlagUp | FlagDrain)
+	s.AddBackend(drained)
+	if len(s.pool.backends) != 1 || drained.index != -1 {
+		t.Fatal("a draining backend got into the heap")
+	}
+}
+
+func TestOutlierEject(t *testing.T) {
+	s := NewScheduler(false, 0)
+	o, err := newOutlierSettings(&config.OutlierConfig{ConsecutiveFailures: 2, BaseEjection: "10s", MaxEjection: "30s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.outlier = o
+
+	b1 := NewBackend("10.0.0.1:80", 0, 0)
+	b2 := NewBackend("10.0.0.2:80", 0, 0)
+	b3 := NewBackend("10.0.0.3:80", 0, 0)
+	for _, b := range []*Backend{b1, b2, b3} {
+		s.AddBackend(b)
+	}
+
+	now := time.Now()
+	s.observe(b1, true, now)
+	s.observe(b1, false, now)
+	s.observe(b1, true, now)
+	if b1.ejected() {
+		t.Fatal("ejected without failures in a row")
+	}
+	s.observe(b1, true, now)
+	if !b1.ejected() || b1.index != -1 || len(s.pool.backends) != 2 {
+		t.Fatal("not ejected after 2 failures in a row")
+	}
+	if ejected := s.ejections(); len(ejected) != 1 || !ejected[b1.address].Equal(now.Add(10*time.Second)) {
+		t.Fatalf("unexpected ejections %v", ejected)
+	}
+
+	// no more than half of the backends
+	s.observe(b2, true, now)
+	s.observe(b2, true, now)
+	if b2.ejected() {
+		t.Fatal("ejected over MaxEjectedPercent")
+	}
+
+	s.returnEjected(now.Add(5 * time.Second))
+	if !b1.ejected() {
+		t.Fatal("returned before the ejection is over")
+	}
+	now = now.Add(10 * time.Second)
+	s.returnEjected(now)
+	if b1.ejected() || b1.index == -1 {
+		t.Fatal("not returned after the ejection")
+	}
+
+	// the period doubles on the next ejection
+	s.observe(b1, true, now)
+	s.observe(b1, true, now)
+	if until := b1.outlier.ejectedUntil.Sub(now); until != 20*time.Second {
+		t.Fatalf("ejected for %s", until)
+	}
+}
@@ -45,7 +45,7 @@ func NewConnTunnel(conn net.Conn) *connTunnel {
 	} else {
 		spdy, err := spdystream.NewConnection(conn, false)
 		if err != nil {
-			log.Printf("spdystream create connection error: %s", err)
+			log.Errorf("spdystream create connection error: %s", err)
 			return nil
 		}
 
@@ -79,7 +79,7 @@ func CreateSpdySession(request *spdySession, ready chan<- *spdySession) {
 		addrs := strings.Split(request.backend.address, ":")
 		if conn, err := NewStreamConn(addrs[0], *streamPort); err == nil {
 			request.spdy = conn
-			log.Printf("Created new session for: %s", request.backend.address)
+			log.Debugf("Created new session for: %s", request.backend.address)
 			break
 		}
 		time.Sleep(time.Second)
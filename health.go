@@ -0,0 +1,71 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package main
+
+import (
+	"fmt"
+	"github.com/zhgwenming/gbalancer/cluster"
+	"github.com/zhgwenming/gbalancer/config"
+	"github.com/zhgwenming/gbalancer/engine"
+	"os"
+	"sort"
+)
+
+// healthReport prints the backend views published by all the instances
+// and the disagreements between them, exits non-zero on disagreements
+func healthReport(settings *config.Configuration) {
+	endpoints := settings.CoordinatorEndpoints()
+	if len(endpoints) == 0 {
+		fmt.Println("error: coordinator need to be configured to report the fleet health")
+		os.Exit(1)
+	}
+
+	client, err := cluster.NewClient(engine.ServiceName, settings.Cluster, endpoints, cluster.DefaultTTL)
+	if err != nil {
+		fmt.Printf("error: %s\n", err)
+		os.Exit(1)
+	}
+
+	views, err := client.FleetHealth()
+	if err != nil {
+		fmt.Printf("error: %s\n", err)
+		os.Exit(1)
+	}
+
+	for _, v := range views {
+		fmt.Printf("%s (pid %s, %s):\n", v.Node, v.Pid, v.Time.Format("2006-01-02 15:04:05"))
+
+		addrs := make([]string, 0, len(v.Backends))
+		for addr := range v.Backends {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+
+		for _, addr := range addrs {
+			b := v.Backends[addr]
+			if b.Up && b.Ejected != nil {
+				fmt.Printf("    %-24s up    active %d, ejected until %s\n", addr, b.Active, b.Ejected.Format("15:04:05"))
+			} else if b.Up {
+				fmt.Printf("    %-24s up    active %d\n", addr, b.Active)
+			} else {
+				fmt.Printf("    %-24s down  %s\n", addr, b.Error)
+			}
+		}
+	}
+
+	disagreements := cluster.Disagreements(views)
+	if len(disagreements) == 0 {
+		fmt.Printf("- %d instances agree on the backends\n", len(views))
+		return
+	}
+
+	for _, d := range disagreements {
+		fmt.Printf("- %s is up on %v, but down on:\n", d.Backend, d.Up)
+		for node, err := range d.Down {
+			fmt.Printf("    %s: %s\n", node, err)
+		}
+	}
+	os.Exit(2)
+}
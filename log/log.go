@@ -5,17 +5,257 @@
 package log
 
 import (
-	"log"
-	"log/syslog"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
-func NewLogger() (l *log.Logger) {
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		name = "warn"
+	}
+
+	for i, n := range levelNames {
+		if n == name {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// the settings shared by all the loggers
+var (
+	mutex        sync.Mutex
+	defaultLevel = LevelInfo
+	levels       = make(map[string]Level)
+	output       sink
+	jsonFormat   bool
+)
+
+func init() {
 	// try to use syslog first
-	if logger, err := syslog.NewLogger(syslog.LOG_NOTICE, 0); err != nil {
-		l = log.New(os.Stderr, "", log.LstdFlags)
+	if s, err := newSyslogSink("", ""); err != nil {
+		output = &writerSink{os.Stderr}
+	} else {
+		output = s
+	}
+}
+
+// Logger writes the messages of a subsystem, the Print family is kept
+// for the callers of the standard logger and logs at the info level
+type Logger struct {
+	subsystem string
+}
+
+func New(subsystem string) *Logger {
+	return &Logger{subsystem}
+}
+
+func NewLogger() *Logger {
+	return New("main")
+}
+
+func (l *Logger) Enabled(level Level) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return level >= l.level()
+}
+
+// level returns the level of the subsystem, called with the mutex held
+func (l *Logger) level() Level {
+	if level, ok := levels[l.subsystem]; ok {
+		return level
+	}
+	return defaultLevel
+}
+
+func (l *Logger) output(level Level, msg string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if level < l.level() {
+		return
+	}
+
+	now := time.Now()
+	msg = strings.TrimRight(msg, "\n")
+
+	var line string
+	if jsonFormat {
+		entry := struct {
+			Time      string `json:"time"`
+			Level     string `json:"level"`
+			Subsystem string `json:"subsystem"`
+			Msg       string `json:"msg"`
+		}{now.Format(time.RFC3339Nano), level.String(), l.subsystem, msg}
+
+		data, _ := json.Marshal(entry)
+		line = string(data)
+	} else {
+		line = fmt.Sprintf("%-5s [%s] %s", strings.ToUpper(level.String()), l.subsystem, msg)
+	}
+
+	output.write(now, level, line)
+}
+
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.output(LevelDebug, fmt.Sprintf(format, v...))
+}
+
+func (l *Logger) Infof(format string, v ...interface{}) {
+	l.output(LevelInfo, fmt.Sprintf(format, v...))
+}
+
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.output(LevelWarn, fmt.Sprintf(format, v...))
+}
+
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.output(LevelError, fmt.Sprintf(format, v...))
+}
+
+func (l *Logger) Print(v ...interface{}) {
+	l.output(LevelInfo, fmt.Sprint(v...))
+}
+
+func (l *Logger) Printf(format string, v ...interface{}) {
+	l.output(LevelInfo, fmt.Sprintf(format, v...))
+}
+
+func (l *Logger) Println(v ...interface{}) {
+	l.output(LevelInfo, fmt.Sprintln(v...))
+}
+
+func (l *Logger) Fatal(v ...interface{}) {
+	l.output(LevelError, fmt.Sprint(v...))
+	os.Exit(1)
+}
+
+func (l *Logger) Fatalf(format string, v ...interface{}) {
+	l.output(LevelError, fmt.Sprintf(format, v...))
+	os.Exit(1)
+}
+
+// SetLevels applies a spec like "info,native=debug,wrangler=warn", the
+// entry without a subsystem is the default level, the subsystems not
+// mentioned fall back to it
+func SetLevels(spec string) error {
+	def := LevelInfo
+	subsystems := make(map[string]Level)
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) == 1 {
+			level, err := ParseLevel(parts[0])
+			if err != nil {
+				return err
+			}
+			def = level
+			continue
+		}
+
+		level, err := ParseLevel(parts[1])
+		if err != nil {
+			return err
+		}
+		subsystems[strings.TrimSpace(parts[0])] = level
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	defaultLevel = def
+	levels = subsystems
+	return nil
+}
+
+// SetLevel changes the level of a subsystem, an empty subsystem sets the
+// default level
+func SetLevel(subsystem string, level Level) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if subsystem == "" {
+		defaultLevel = level
 	} else {
-		l = logger
+		levels[subsystem] = level
 	}
-	return
+}
+
+func SetJSON(enabled bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	jsonFormat = enabled
+}
+
+func setOutput(s sink) {
+	mutex.Lock()
+	old := output
+	output = s
+	mutex.Unlock()
+
+	old.close()
+}
+
+func SetOutput(w io.Writer) {
+	setOutput(&writerSink{w})
+}
+
+// SetFile sends the logs to the file, it's reopened by Reopen after rotated
+func SetFile(path string) error {
+	s := &fileSink{path: path}
+	if err := s.reopen(); err != nil {
+		return err
+	}
+	setOutput(s)
+	return nil
+}
+
+// SetSyslog sends the logs to syslog with the facility and tag, the
+// program name is used as the tag if it's empty
+func SetSyslog(facility, tag string) error {
+	s, err := newSyslogSink(facility, tag)
+	if err != nil {
+		return err
+	}
+	setOutput(s)
+	return nil
+}
+
+// Reopen reopens the log file for logrotate, it's a no-op for the other sinks
+func Reopen() error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return output.reopen()
 }
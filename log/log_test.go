@@ -0,0 +1,98 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+
+	if err := SetLevels("warn,native=debug"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetLevels("")
+
+	native, wrangler := New("native"), New("wrangler")
+	native.Debugf("session created")
+	wrangler.Printf("server up")
+	wrangler.Warnf("server down")
+
+	out := buf.String()
+	if !strings.Contains(out, "DEBUG [native] session created") {
+		t.Errorf("debug message of native missing: %q", out)
+	}
+	if strings.Contains(out, "server up") {
+		t.Errorf("info message of wrangler not filtered: %q", out)
+	}
+	if !strings.Contains(out, "WARN  [wrangler] server down") {
+		t.Errorf("warn message of wrangler missing: %q", out)
+	}
+
+	if err := SetLevels("info,native=verbose"); err == nil {
+		t.Errorf("bad level accepted")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+
+	SetJSON(true)
+	defer SetJSON(false)
+
+	New("engine").Errorf("listen error: %s\n", "in use")
+
+	var entry map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("%s: %q", err, buf.String())
+	}
+	if entry["level"] != "error" || entry["subsystem"] != "engine" || entry["msg"] != "listen error: in use" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "test.log")
+	if err := SetFile(name); err != nil {
+		t.Fatal(err)
+	}
+	defer SetOutput(os.Stderr)
+
+	l := New("main")
+	l.Printf("before rotate")
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	l.Printf("after rotate")
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "before") || !strings.Contains(string(data), "after rotate") {
+		t.Errorf("unexpected content after reopen: %q", data)
+	}
+}
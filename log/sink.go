@@ -0,0 +1,132 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"time"
+)
+
+const (
+	timeFormat = "2006/01/02 15:04:05"
+)
+
+// sink is where the formatted lines go, it's called with the mutex held
+type sink interface {
+	write(now time.Time, level Level, line string)
+	reopen() error
+	close()
+}
+
+// prefix returns the timestamp of the text lines, the json ones carry it already
+func prefix(now time.Time) string {
+	if jsonFormat {
+		return ""
+	}
+	return now.Format(timeFormat) + " "
+}
+
+type writerSink struct {
+	w io.Writer
+}
+
+func (s *writerSink) write(now time.Time, level Level, line string) {
+	fmt.Fprintf(s.w, "%s%s\n", prefix(now), line)
+}
+
+func (s *writerSink) reopen() error {
+	return nil
+}
+
+func (s *writerSink) close() {
+}
+
+type fileSink struct {
+	path string
+	file *os.File
+}
+
+func (s *fileSink) write(now time.Time, level Level, line string) {
+	fmt.Fprintf(s.file, "%s%s\n", prefix(now), line)
+}
+
+func (s *fileSink) reopen() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = file
+	return nil
+}
+
+func (s *fileSink) close() {
+	if s.file != nil {
+		s.file.Close()
+	}
+}
+
+var facilities = map[string]syslog.Priority{
+	"kern":   syslog.LOG_KERN,
+	"user":   syslog.LOG_USER,
+	"daemon": syslog.LOG_DAEMON,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// syslogSink maps the levels to the syslog severities, syslog adds the
+// timestamp itself
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+func newSyslogSink(facility, tag string) (*syslogSink, error) {
+	priority := syslog.LOG_USER
+	if facility != "" {
+		var ok bool
+		if priority, ok = facilities[facility]; !ok {
+			return nil, fmt.Errorf("unknown syslog facility %q", facility)
+		}
+	}
+
+	writer, err := syslog.New(priority|syslog.LOG_NOTICE, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer}, nil
+}
+
+func (s *syslogSink) write(now time.Time, level Level, line string) {
+	switch level {
+	case LevelDebug:
+		s.writer.Debug(line)
+	case LevelInfo:
+		s.writer.Info(line)
+	case LevelWarn:
+		s.writer.Warning(line)
+	default:
+		s.writer.Err(line)
+	}
+}
+
+func (s *syslogSink) reopen() error {
+	return nil
+}
+
+func (s *syslogSink) close() {
+	s.writer.Close()
+}
@@ -12,22 +12,35 @@ import (
 	"github.com/zhgwenming/gbalancer/engine"
 	logger "github.com/zhgwenming/gbalancer/log"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
+	"time"
 )
 
 var (
 	VERSION string
 )
 
+const (
+	// EX_CONFIG of sysexits.h, restarting won't help
+	exitConfig = 78
+)
+
 var (
 	wgroup       = &sync.WaitGroup{}
 	log          = logger.NewLogger()
 	configFile   = flag.String("config", "/etc/gbalancer/gbalancer.json", "Configuration file")
 	printVersion = flag.Bool("version", false, "print gbalancer version")
 
-	daemonMode = flag.Bool("daemon", false, "daemon mode")
-	pidFile    = flag.String("pidfile", "", "pid file")
+	daemonMode   = flag.Bool("daemon", false, "daemon mode")
+	pidFile      = flag.String("pidfile", "", "pid file")
+	drainTimeout = flag.Duration("drain", 5*time.Minute, "max time to wait for the connections to finish on upgrade")
+	noRestart    = flag.String("norestart", strconv.Itoa(exitConfig), "comma separated exit codes the worker won't be restarted on")
+	crashLimit   = flag.Int("crashlimit", 10, "give up after the worker crashed so many times in a row, 0 means never")
 )
 
 func PrintVersion() {
@@ -39,11 +52,17 @@ type Server struct {
 	settings *config.Configuration
 	wgroup   *sync.WaitGroup
 	done     chan struct{}
+	active   func() map[string]uint
 }
 
 func (s *Server) Serve() {
 	// create the service goroutine
-	s.done = engine.Serve(s.settings, s.wgroup)
+	var err error
+	if s.done, s.active, err = engine.Serve(s.settings, s.wgroup); err != nil {
+		fmt.Printf("error: %s\n", err)
+		log.Print("error:", err)
+		os.Exit(exitConfig)
+	}
 }
 
 func (s *Server) Stop() {
@@ -51,6 +70,33 @@ func (s *Server) Stop() {
 	s.wgroup.Wait()
 }
 
+// Drain waits for the forwarded connections to finish after the listeners
+// got handed over on upgrade
+func (s *Server) Drain() {
+	if s.active == nil {
+		return
+	}
+
+	deadline := time.Now().Add(*drainTimeout)
+	for {
+		var ongoing uint
+		for _, n := range s.active() {
+			ongoing += n
+		}
+
+		if ongoing == 0 {
+			log.Printf("all connections finished")
+			return
+		}
+
+		if time.Now().After(deadline) {
+			log.Printf("exiting with %d connections ongoing", ongoing)
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
+
 func main() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
@@ -63,7 +109,8 @@ func main() {
 	if *daemonMode {
 		if err := config.CheckFile(*configFile); err != nil {
 			fmt.Println(err)
-			log.Fatal(err)
+			log.Print(err)
+			os.Exit(exitConfig)
 		}
 	}
 
@@ -71,12 +118,48 @@ func main() {
 	settings, err := config.LoadConfig(*configFile)
 	if err != nil {
 		fmt.Printf("error: %s\n", err)
-		log.Fatal("error:", err)
+		log.Print("error:", err)
+		os.Exit(exitConfig)
+	}
+
+	if flag.Arg(0) == "health" {
+		healthReport(settings)
+		return
+	}
+
+	if err := setupLog(settings.Log); err != nil {
+		fmt.Printf("error: %s\n", err)
+		os.Exit(exitConfig)
 	}
-	log.Printf(settings.ListenInfo())
+
+	// the panics of the background daemon go to the log file as well
+	if filepath.IsAbs(settings.Log.Output) {
+		nestor.DefaultSupervisor.SetOutput(settings.Log.Output)
+	}
+
+	// reload the log settings and reopen the log file for logrotate
+	nestor.HandleSignal(syscall.SIGUSR1, func() {
+		if s, err := config.LoadConfig(*configFile); err != nil {
+			log.Warnf("log settings not reloaded - %s", err)
+			logger.Reopen()
+		} else if err := setupLog(s.Log); err != nil {
+			log.Warnf("log settings not reloaded - %s", err)
+			logger.Reopen()
+		}
+	})
+
+	log.Print(settings.ListenInfo())
 
 	srv := &Server{settings: settings, wgroup: wgroup}
 
+	codes, err := exitCodes(*noRestart)
+	if err != nil {
+		fmt.Printf("error: %s\n", err)
+		os.Exit(exitConfig)
+	}
+	nestor.DefaultSupervisor.NoRestart = codes
+	nestor.DefaultSupervisor.CrashLimit = *crashLimit
+
 	foreground := !*daemonMode
 	n := nestor.Handle(*pidFile, foreground, srv)
 
@@ -84,3 +167,40 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+func exitCodes(list string) ([]int, error) {
+	codes := make([]int, 0)
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		code, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("bad exit code %q", field)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+func setupLog(c config.LogConfig) error {
+	if err := logger.SetLevels(c.Level); err != nil {
+		return err
+	}
+	logger.SetJSON(c.JSON)
+
+	switch {
+	case c.Output == "stderr":
+		logger.SetOutput(os.Stderr)
+	case c.Output == "syslog" || c.Facility != "" || c.Tag != "":
+		return logger.SetSyslog(c.Facility, c.Tag)
+	case c.Output != "":
+		if !filepath.IsAbs(c.Output) {
+			return fmt.Errorf("log file need to be specified with absolute path - %s", c.Output)
+		}
+		return logger.SetFile(c.Output)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package mysqlproto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// the capabilities the proxy offers to the clients, the responses are
+// passed through so the ones changing the format are left out
+const ServerCapabilities = CLIENT_LONG_PASSWORD | CLIENT_FOUND_ROWS | CLIENT_LONG_FLAG |
+	CLIENT_CONNECT_WITH_DB | CLIENT_NO_SCHEMA | CLIENT_ODBC | CLIENT_IGNORE_SPACE |
+	CLIENT_PROTOCOL_41 | CLIENT_INTERACTIVE | CLIENT_IGNORE_SIGPIPE | CLIENT_TRANSACTIONS |
+	CLIENT_SECURE_CONNECTION | CLIENT_MULTI_STATEMENTS | CLIENT_MULTI_RESULTS |
+	CLIENT_PS_MULTI_RESULTS | CLIENT_PLUGIN_AUTH | CLIENT_PLUGIN_AUTH_LENENC_CLIENT_DATA
+
+// Login is what we use to authenticate to a server on behalf of a client
+type Login struct {
+	User         string
+	Password     string
+	Database     string
+	Charset      byte
+	Capabilities uint32
+}
+
+// Connect runs the client side of the handshake, the agreed capabilities
+// are the ones of the login the server supports
+func Connect(conn *Conn, login *Login) (*Handshake, error) {
+	h, err := ReadHandshake(conn)
+	if err != nil {
+		return nil, err
+	}
+	return h, Authenticate(conn, h, login)
+}
+
+// ReadHandshake waits for the initial packet of the server
+func ReadHandshake(conn *Conn) (*Handshake, error) {
+	conn.ResetSeq()
+	p, err := conn.ReadPacket()
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := ParseHandshake(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Capabilities&CLIENT_PROTOCOL_41 == 0 || h.Capabilities&CLIENT_SECURE_CONNECTION == 0 {
+		return nil, fmt.Errorf("server %s is too old", h.Version)
+	}
+	return h, nil
+}
+
+// Authenticate answers the handshake of the server with the login
+func Authenticate(conn *Conn, h *Handshake, login *Login) error {
+
+	caps := login.Capabilities&h.Capabilities | CLIENT_PROTOCOL_41 | CLIENT_SECURE_CONNECTION
+	caps &^= CLIENT_CONNECT_WITH_DB | CLIENT_SSL | CLIENT_COMPRESS | CLIENT_CONNECT_ATTRS | CLIENT_LOCAL_FILES
+	if login.Database != "" {
+		caps |= CLIENT_CONNECT_WITH_DB
+	}
+	if h.Capabilities&CLIENT_PLUGIN_AUTH != 0 {
+		caps |= CLIENT_PLUGIN_AUTH
+	}
+
+	plugin := h.AuthPlugin
+	if plugin != CachingSha2 {
+		plugin = NativePassword
+	}
+
+	resp := &HandshakeResponse{
+		Capabilities:  caps,
+		MaxPacketSize: MaxPacketSize,
+		Charset:       login.Charset,
+		User:          login.User,
+		Database:      login.Database,
+		AuthPlugin:    plugin,
+	}
+	resp.AuthResponse = scramble(plugin, h.Scramble, login.Password)
+
+	if err := conn.WritePacket(resp.Packet()); err != nil {
+		return err
+	}
+	conn.Capabilities = caps
+
+	return authResult(conn, plugin, h.Scramble, login.Password)
+}
+
+func scramble(plugin string, data []byte, password string) []byte {
+	if plugin == CachingSha2 {
+		return ScrambleSha2(data, password)
+	}
+	return ScrambleNative(data, password)
+}
+
+// authResult follows the auth switches and the caching_sha2_password
+// exchanges until the server accepts or rejects us
+func authResult(conn *Conn, plugin string, data []byte, password string) error {
+	for {
+		p, err := conn.ReadPacket()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case IsOK(p):
+			return nil
+		case IsErr(p):
+			return ParseError(p)
+		case p[0] == AUTH_SWITCH_PACKET:
+			name, n, err := readNulString(p[1:])
+			if err != nil {
+				return err
+			}
+			plugin = string(name)
+			if plugin != NativePassword && plugin != CachingSha2 {
+				return fmt.Errorf("unsupported auth plugin %s", plugin)
+			}
+
+			data = bytes.TrimRight(p[1+n:], "\x00")
+			if err := conn.WritePacket(scramble(plugin, data, password)); err != nil {
+				return err
+			}
+		case p[0] == AUTH_MORE_DATA && plugin == CachingSha2 && len(p) == 2:
+			switch p[1] {
+			case 3:
+				// fast auth succeeded, the OK follows
+			case 4:
+				// full auth, ask for the public key as we're not on TLS
+				if err := conn.WritePacket([]byte{2}); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unexpected caching_sha2_password state %d", p[1])
+			}
+		case p[0] == AUTH_MORE_DATA && plugin == CachingSha2:
+			encrypted, err := encryptPassword(p[1:], data, password)
+			if err != nil {
+				return err
+			}
+			if err := conn.WritePacket(encrypted); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected auth packet 0x%02x", p[0])
+		}
+	}
+}
+
+// encryptPassword encrypts the password with the public key of the server
+// for the full authentication of caching_sha2_password
+func encryptPassword(key, data []byte, password string) ([]byte, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, fmt.Errorf("bad public key from server")
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("bad public key from server")
+	}
+
+	plain := append([]byte(password), 0)
+	for i := range plain {
+		plain[i] ^= data[i%len(data)]
+	}
+	return rsa.EncryptOAEP(sha1.New(), rand.Reader, rsaPub, plain, nil)
+}
+
+// Accept runs the server side of the handshake with mysql_native_password,
+// password returns the password of the user or false for unknown ones.
+// The caller sends the final OK or ERR packet.
+func Accept(conn *Conn, version string, id uint32, password func(user string) (string, bool)) (*HandshakeResponse, error) {
+	data, err := NewScramble()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := greet(conn, version, id, data); err != nil {
+		return nil, err
+	}
+
+	p, err := conn.ReadPacket()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ParseHandshakeResponse(p)
+	if err != nil {
+		conn.WriteError(ER_HANDSHAKE_ERROR, "08S01", "Bad handshake")
+		return nil, err
+	}
+	if resp.Capabilities&CLIENT_SSL != 0 {
+		conn.WriteError(ER_NOT_SUPPORTED_YET, "08S01", "SSL is not supported")
+		return nil, fmt.Errorf("client %s requested SSL", resp.User)
+	}
+	resp.Capabilities &= ServerCapabilities
+	conn.Capabilities = resp.Capabilities
+
+	// ask the clients defaulting to other plugins to switch
+	if resp.AuthPlugin != "" && resp.AuthPlugin != NativePassword {
+		switchReq := append([]byte{AUTH_SWITCH_PACKET}, NativePassword...)
+		switchReq = append(switchReq, 0)
+		switchReq = append(switchReq, data...)
+		switchReq = append(switchReq, 0)
+		if err := conn.WritePacket(switchReq); err != nil {
+			return nil, err
+		}
+
+		if resp.AuthResponse, err = conn.ReadPacket(); err != nil {
+			return nil, err
+		}
+		resp.AuthPlugin = NativePassword
+	}
+
+	pass, ok := password(resp.User)
+	expected := ScrambleNative(data, pass)
+	if !ok || subtle.ConstantTimeCompare(expected, resp.AuthResponse) != 1 {
+		using := "NO"
+		if len(resp.AuthResponse) > 0 {
+			using = "YES"
+		}
+		msg := fmt.Sprintf("Access denied for user '%s' (using password: %s)", resp.User, using)
+		conn.WriteError(ER_ACCESS_DENIED_ERROR, "28000", msg)
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return resp, nil
+}
+
+// Reject turns down the client with the error once it answered the
+// handshake, so the drivers report the error instead of a lost connection
+func Reject(conn *Conn, version string, e *Error) error {
+	data, err := NewScramble()
+	if err != nil {
+		return err
+	}
+
+	if err := greet(conn, version, 0, data); err != nil {
+		return err
+	}
+
+	if _, err := conn.ReadPacket(); err != nil {
+		return err
+	}
+	return conn.WritePacket(e.Packet())
+}
+
+func greet(conn *Conn, version string, id uint32, data []byte) error {
+	h := &Handshake{
+		Version:      version,
+		ConnectionId: id,
+		Scramble:     data,
+		Capabilities: ServerCapabilities,
+		Charset:      DefaultCharset,
+		Status:       SERVER_STATUS_AUTOCOMMIT,
+		AuthPlugin:   NativePassword,
+	}
+
+	conn.ResetSeq()
+	return conn.WritePacket(h.Packet())
+}
@@ -0,0 +1,119 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+// Package mysqlproto implements the parts of the MySQL client/server
+// protocol needed to proxy the connections: the packets, the handshake and
+// authentication on both sides, and the boundaries of the responses.
+package mysqlproto
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+)
+
+// Conn reads and writes the packets of one side of a connection, the
+// sequence id is tracked per command
+type Conn struct {
+	net.Conn
+	reader *bufio.Reader
+	seq    byte
+	// capabilities agreed in the handshake
+	Capabilities uint32
+}
+
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{Conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// Reader returns the buffered reader for passing the rest of the stream through
+func (c *Conn) Reader() io.Reader {
+	return c.reader
+}
+
+// ResetSeq starts a new command
+func (c *Conn) ResetSeq() {
+	c.seq = 0
+}
+
+// ReadPacket returns the payload of a packet, the payloads over 16M are
+// joined together
+func (c *Conn) ReadPacket() ([]byte, error) {
+	var payload []byte
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+			return nil, err
+		}
+
+		length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+		if header[3] != c.seq {
+			return nil, fmt.Errorf("packet out of order, got %d, expected %d", header[3], c.seq)
+		}
+		c.seq++
+
+		start := len(payload)
+		payload = append(payload, make([]byte, length)...)
+		if _, err := io.ReadFull(c.reader, payload[start:]); err != nil {
+			return nil, err
+		}
+
+		if length < MaxPacketSize {
+			return payload, nil
+		}
+	}
+}
+
+// WritePacket sends the payload, it's split if it's over 16M
+func (c *Conn) WritePacket(payload []byte) error {
+	for {
+		length := len(payload)
+		if length > MaxPacketSize {
+			length = MaxPacketSize
+		}
+
+		data := make([]byte, 4+length)
+		data[0] = byte(length)
+		data[1] = byte(length >> 8)
+		data[2] = byte(length >> 16)
+		data[3] = c.seq
+		copy(data[4:], payload[:length])
+		c.seq++
+
+		if _, err := c.Conn.Write(data); err != nil {
+			return err
+		}
+
+		payload = payload[length:]
+		// an empty packet follows a payload of exactly 16M
+		if length < MaxPacketSize {
+			return nil
+		}
+	}
+}
+
+// WriteCommand starts a new command with the packet
+func (c *Conn) WriteCommand(payload []byte) error {
+	c.ResetSeq()
+	return c.WritePacket(payload)
+}
+
+// ReadCommand waits for a new command
+func (c *Conn) ReadCommand() ([]byte, error) {
+	c.ResetSeq()
+	payload, err := c.ReadPacket()
+	if err == nil && len(payload) == 0 {
+		err = fmt.Errorf("empty command packet")
+	}
+	return payload, err
+}
+
+func (c *Conn) WriteOK(status uint16) error {
+	return c.WritePacket(OKPacket(0, 0, status, 0))
+}
+
+func (c *Conn) WriteError(code uint16, state, message string) error {
+	return c.WritePacket(ErrorPacket(code, state, message))
+}
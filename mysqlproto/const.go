@@ -0,0 +1,110 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package mysqlproto
+
+// capability flags
+const (
+	CLIENT_LONG_PASSWORD uint32 = 1 << iota
+	CLIENT_FOUND_ROWS
+	CLIENT_LONG_FLAG
+	CLIENT_CONNECT_WITH_DB
+	CLIENT_NO_SCHEMA
+	CLIENT_COMPRESS
+	CLIENT_ODBC
+	CLIENT_LOCAL_FILES
+	CLIENT_IGNORE_SPACE
+	CLIENT_PROTOCOL_41
+	CLIENT_INTERACTIVE
+	CLIENT_SSL
+	CLIENT_IGNORE_SIGPIPE
+	CLIENT_TRANSACTIONS
+	CLIENT_RESERVED
+	CLIENT_SECURE_CONNECTION
+	CLIENT_MULTI_STATEMENTS
+	CLIENT_MULTI_RESULTS
+	CLIENT_PS_MULTI_RESULTS
+	CLIENT_PLUGIN_AUTH
+	CLIENT_CONNECT_ATTRS
+	CLIENT_PLUGIN_AUTH_LENENC_CLIENT_DATA
+	CLIENT_CAN_HANDLE_EXPIRED_PASSWORDS
+	CLIENT_SESSION_TRACK
+	CLIENT_DEPRECATE_EOF
+)
+
+// server status flags
+const (
+	SERVER_STATUS_IN_TRANS             uint16 = 0x0001
+	SERVER_STATUS_AUTOCOMMIT           uint16 = 0x0002
+	SERVER_MORE_RESULTS_EXISTS         uint16 = 0x0008
+	SERVER_STATUS_IN_TRANS_READONLY    uint16 = 0x2000
+	SERVER_SESSION_STATE_CHANGED       uint16 = 0x4000
+	SERVER_STATUS_LAST_ROW_SENT        uint16 = 0x0080
+	SERVER_STATUS_CURSOR_EXISTS        uint16 = 0x0040
+	SERVER_STATUS_NO_BACKSLASH_ESCAPES uint16 = 0x0200
+)
+
+// commands
+const (
+	COM_SLEEP byte = iota
+	COM_QUIT
+	COM_INIT_DB
+	COM_QUERY
+	COM_FIELD_LIST
+	COM_CREATE_DB
+	COM_DROP_DB
+	COM_REFRESH
+	COM_SHUTDOWN
+	COM_STATISTICS
+	COM_PROCESS_INFO
+	COM_CONNECT
+	COM_PROCESS_KILL
+	COM_DEBUG
+	COM_PING
+	COM_TIME
+	COM_DELAYED_INSERT
+	COM_CHANGE_USER
+	COM_BINLOG_DUMP
+	COM_TABLE_DUMP
+	COM_CONNECT_OUT
+	COM_REGISTER_SLAVE
+	COM_STMT_PREPARE
+	COM_STMT_EXECUTE
+	COM_STMT_SEND_LONG_DATA
+	COM_STMT_CLOSE
+	COM_STMT_RESET
+	COM_SET_OPTION
+	COM_STMT_FETCH
+	COM_DAEMON
+	COM_BINLOG_DUMP_GTID
+	COM_RESET_CONNECTION
+)
+
+// packet headers
+const (
+	OK_PACKET          byte = 0x00
+	AUTH_MORE_DATA     byte = 0x01
+	LOCAL_INFILE       byte = 0xfb
+	EOF_PACKET         byte = 0xfe
+	AUTH_SWITCH_PACKET byte = 0xfe
+	ERR_PACKET         byte = 0xff
+)
+
+// error codes used by the proxy
+const (
+	ER_ACCESS_DENIED_ERROR uint16 = 1045
+	ER_UNKNOWN_COM_ERROR   uint16 = 1047
+	ER_CON_COUNT_ERROR     uint16 = 1040
+	ER_HANDSHAKE_ERROR     uint16 = 1043
+	ER_NOT_SUPPORTED_YET   uint16 = 1235
+	CR_CONN_HOST_ERROR     uint16 = 2003
+)
+
+const (
+	NativePassword = "mysql_native_password"
+	CachingSha2    = "caching_sha2_password"
+
+	DefaultCharset byte = 33 // utf8_general_ci
+	MaxPacketSize       = 1<<24 - 1
+)
@@ -0,0 +1,264 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package mysqlproto
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// Handshake is the initial packet of the server
+type Handshake struct {
+	Version      string
+	ConnectionId uint32
+	Scramble     []byte
+	Capabilities uint32
+	Charset      byte
+	Status       uint16
+	AuthPlugin   string
+}
+
+func (h *Handshake) Packet() []byte {
+	b := []byte{10}
+	b = append(b, h.Version...)
+	b = append(b, 0)
+
+	var id [4]byte
+	binary.LittleEndian.PutUint32(id[:], h.ConnectionId)
+	b = append(b, id[:]...)
+
+	b = append(b, h.Scramble[:8]...)
+	b = append(b, 0)
+	b = append(b, byte(h.Capabilities), byte(h.Capabilities>>8))
+	b = append(b, h.Charset)
+	b = append(b, byte(h.Status), byte(h.Status>>8))
+	b = append(b, byte(h.Capabilities>>16), byte(h.Capabilities>>24))
+	b = append(b, byte(len(h.Scramble)+1))
+	b = append(b, make([]byte, 10)...)
+	b = append(b, h.Scramble[8:]...)
+	b = append(b, 0)
+	b = append(b, h.AuthPlugin...)
+	return append(b, 0)
+}
+
+func ParseHandshake(p []byte) (*Handshake, error) {
+	if len(p) == 0 || p[0] != 10 {
+		if IsErr(p) {
+			return nil, ParseError(p)
+		}
+		return nil, fmt.Errorf("unsupported protocol version")
+	}
+
+	h := &Handshake{}
+	version, n, err := readNulString(p[1:])
+	if err != nil {
+		return nil, err
+	}
+	h.Version = string(version)
+	pos := 1 + n
+
+	if len(p) < pos+4+8+1+2 {
+		return nil, fmt.Errorf("malformed handshake")
+	}
+	h.ConnectionId = binary.LittleEndian.Uint32(p[pos:])
+	pos += 4
+	h.Scramble = append([]byte{}, p[pos:pos+8]...)
+	pos += 9
+	h.Capabilities = uint32(binary.LittleEndian.Uint16(p[pos:]))
+	pos += 2
+
+	if len(p) < pos+1+2+2+1+10 {
+		return h, nil
+	}
+	h.Charset = p[pos]
+	h.Status = binary.LittleEndian.Uint16(p[pos+1:])
+	h.Capabilities |= uint32(binary.LittleEndian.Uint16(p[pos+3:])) << 16
+	authLen := int(p[pos+5])
+	pos += 16
+
+	if h.Capabilities&CLIENT_SECURE_CONNECTION != 0 {
+		size := authLen - 8
+		if size < 13 {
+			size = 13
+		}
+		if len(p) < pos+size {
+			return nil, fmt.Errorf("malformed handshake")
+		}
+		// without the trailing NUL
+		h.Scramble = append(h.Scramble, p[pos:pos+size-1]...)
+		pos += size
+	}
+
+	if h.Capabilities&CLIENT_PLUGIN_AUTH != 0 && pos < len(p) {
+		plugin, _, err := readNulString(p[pos:])
+		if err != nil {
+			// some servers omit the NUL
+			plugin = p[pos:]
+		}
+		h.AuthPlugin = string(plugin)
+	}
+	return h, nil
+}
+
+// HandshakeResponse is the login request of the client
+type HandshakeResponse struct {
+	Capabilities  uint32
+	MaxPacketSize uint32
+	Charset       byte
+	User          string
+	AuthResponse  []byte
+	Database      string
+	AuthPlugin    string
+}
+
+func (r *HandshakeResponse) Packet() []byte {
+	b := make([]byte, 32)
+	binary.LittleEndian.PutUint32(b, r.Capabilities)
+	binary.LittleEndian.PutUint32(b[4:], r.MaxPacketSize)
+	b[8] = r.Charset
+
+	b = append(b, r.User...)
+	b = append(b, 0)
+
+	if r.Capabilities&CLIENT_PLUGIN_AUTH_LENENC_CLIENT_DATA != 0 {
+		b = appendLenEncString(b, r.AuthResponse)
+	} else {
+		b = append(b, byte(len(r.AuthResponse)))
+		b = append(b, r.AuthResponse...)
+	}
+
+	if r.Capabilities&CLIENT_CONNECT_WITH_DB != 0 {
+		b = append(b, r.Database...)
+		b = append(b, 0)
+	}
+
+	if r.Capabilities&CLIENT_PLUGIN_AUTH != 0 {
+		b = append(b, r.AuthPlugin...)
+		b = append(b, 0)
+	}
+	return b
+}
+
+func ParseHandshakeResponse(p []byte) (*HandshakeResponse, error) {
+	if len(p) < 32 {
+		return nil, fmt.Errorf("malformed handshake response")
+	}
+
+	r := &HandshakeResponse{}
+	r.Capabilities = binary.LittleEndian.Uint32(p)
+	if r.Capabilities&CLIENT_PROTOCOL_41 == 0 {
+		return nil, fmt.Errorf("pre 4.1 clients are not supported")
+	}
+	r.MaxPacketSize = binary.LittleEndian.Uint32(p[4:])
+	r.Charset = p[8]
+	pos := 32
+
+	user, n, err := readNulString(p[pos:])
+	if err != nil {
+		return nil, err
+	}
+	r.User = string(user)
+	pos += n
+
+	switch {
+	case r.Capabilities&CLIENT_PLUGIN_AUTH_LENENC_CLIENT_DATA != 0:
+		size, n, err := readLenEnc(p[pos:])
+		if err != nil || len(p) < pos+n+int(size) {
+			return nil, fmt.Errorf("malformed handshake response")
+		}
+		r.AuthResponse = p[pos+n : pos+n+int(size)]
+		pos += n + int(size)
+	case r.Capabilities&CLIENT_SECURE_CONNECTION != 0:
+		if len(p) < pos+1 || len(p) < pos+1+int(p[pos]) {
+			return nil, fmt.Errorf("malformed handshake response")
+		}
+		r.AuthResponse = p[pos+1 : pos+1+int(p[pos])]
+		pos += 1 + int(p[pos])
+	default:
+		auth, n, err := readNulString(p[pos:])
+		if err != nil {
+			return nil, err
+		}
+		r.AuthResponse = auth
+		pos += n
+	}
+
+	if r.Capabilities&CLIENT_CONNECT_WITH_DB != 0 && pos < len(p) {
+		db, n, err := readNulString(p[pos:])
+		if err != nil {
+			return nil, err
+		}
+		r.Database = string(db)
+		pos += n
+	}
+
+	if r.Capabilities&CLIENT_PLUGIN_AUTH != 0 && pos < len(p) {
+		plugin, _, err := readNulString(p[pos:])
+		if err != nil {
+			plugin = p[pos:]
+		}
+		r.AuthPlugin = string(plugin)
+	}
+	return r, nil
+}
+
+// NewScramble returns the random auth data of a handshake
+func NewScramble() ([]byte, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	// keep it printable and free of NUL like the server does
+	for i := range b {
+		b[i] = b[i]%94 + 33
+	}
+	return b, nil
+}
+
+// ScrambleNative computes the mysql_native_password response:
+// SHA1(password) XOR SHA1(scramble + SHA1(SHA1(password)))
+func ScrambleNative(scramble []byte, password string) []byte {
+	if password == "" {
+		return []byte{}
+	}
+
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+
+	h := sha1.New()
+	h.Write(scramble)
+	h.Write(stage2[:])
+	token := h.Sum(nil)
+
+	for i := range token {
+		token[i] ^= stage1[i]
+	}
+	return token
+}
+
+// ScrambleSha2 computes the fast path response of caching_sha2_password:
+// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + scramble)
+func ScrambleSha2(scramble []byte, password string) []byte {
+	if password == "" {
+		return []byte{}
+	}
+
+	stage1 := sha256.Sum256([]byte(password))
+	stage2 := sha256.Sum256(stage1[:])
+
+	h := sha256.New()
+	h.Write(stage2[:])
+	h.Write(scramble)
+	token := h.Sum(nil)
+
+	for i := range token {
+		token[i] ^= stage1[i]
+	}
+	return token
+}
@@ -0,0 +1,123 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package mysqlproto
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func pipe() (*Conn, *Conn) {
+	c1, c2 := net.Pipe()
+	return NewConn(c1), NewConn(c2)
+}
+
+func TestLenEnc(t *testing.T) {
+	for _, n := range []uint64{0, 250, 251, 1<<16 - 1, 1 << 16, 1<<24 - 1, 1 << 24, 1<<64 - 1} {
+		b := appendLenEnc(nil, n)
+		got, size, err := readLenEnc(b)
+		if err != nil || got != n || size != len(b) {
+			t.Errorf("%d: got %d %d %v", n, got, size, err)
+		}
+	}
+}
+
+func TestLargePacket(t *testing.T) {
+	server, client := pipe()
+	payload := bytes.Repeat([]byte{'x'}, MaxPacketSize+10)
+
+	go client.WriteCommand(payload)
+	server.ResetSeq()
+	got, err := server.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("got %d bytes, expected %d", len(got), len(payload))
+	}
+}
+
+func TestAuth(t *testing.T) {
+	users := func(user string) (string, bool) {
+		if user == "app" {
+			return "secret", true
+		}
+		return "", false
+	}
+
+	for _, pass := range []string{"secret", "wrong"} {
+		server, client := pipe()
+		done := make(chan error, 1)
+		go func() {
+			_, err := Connect(client, &Login{User: "app", Password: pass, Database: "db", Charset: DefaultCharset})
+			done <- err
+		}()
+
+		resp, err := Accept(server, "5.7.0", 1, users)
+		if pass == "wrong" {
+			if err == nil {
+				t.Fatal("accepted a wrong password")
+			}
+			if e, ok := (<-done).(*Error); !ok || e.Code != ER_ACCESS_DENIED_ERROR {
+				t.Fatalf("expected access denied, got %v", e)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.User != "app" || resp.Database != "db" {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+		server.WriteOK(SERVER_STATUS_AUTOCOMMIT)
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReject(t *testing.T) {
+	server, client := pipe()
+	go Reject(server, "5.7.0", &Error{CR_CONN_HOST_ERROR, "HY000", "no healthy galera node"})
+
+	_, err := Connect(client, &Login{User: "app", Charset: DefaultCharset})
+	if e, ok := err.(*Error); !ok || e.Code != CR_CONN_HOST_ERROR || e.Message != "no healthy galera node" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestReadResult(t *testing.T) {
+	server, client := pipe()
+	eof := []byte{EOF_PACKET, 0, 0, byte(SERVER_STATUS_AUTOCOMMIT | SERVER_MORE_RESULTS_EXISTS), 0}
+	packets := [][]byte{
+		// a result set of one column and two rows
+		{1}, []byte("column"), eof, []byte("row1"), []byte("row2"), eof,
+		// then the OK of the call
+		OKPacket(0, 0, SERVER_STATUS_AUTOCOMMIT|SERVER_STATUS_IN_TRANS, 0),
+	}
+
+	go func() {
+		server.ResetSeq()
+		for _, p := range packets {
+			server.WritePacket(p)
+		}
+	}()
+
+	var relayed int
+	client.ResetSeq()
+	status, known, err := client.ReadResult(func(p []byte) error {
+		relayed++
+		return nil
+	})
+
+	if err != nil || !known {
+		t.Fatal(known, err)
+	}
+	if relayed != len(packets) || status&SERVER_STATUS_IN_TRANS == 0 {
+		t.Fatalf("relayed %d packets with status %x", relayed, status)
+	}
+}
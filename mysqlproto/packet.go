@@ -0,0 +1,162 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package mysqlproto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// length encoded integers and strings
+
+func appendLenEnc(b []byte, n uint64) []byte {
+	switch {
+	case n < 251:
+		return append(b, byte(n))
+	case n < 1<<16:
+		return append(b, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		return append(b, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	}
+	b = append(b, 0xfe)
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], n)
+	return append(b, buf[:]...)
+}
+
+func appendLenEncString(b []byte, s []byte) []byte {
+	b = appendLenEnc(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// readLenEnc returns the integer and the bytes it took
+func readLenEnc(b []byte) (uint64, int, error) {
+	if len(b) == 0 {
+		return 0, 0, fmt.Errorf("malformed packet")
+	}
+
+	var size int
+	switch b[0] {
+	case 0xfc:
+		size = 2
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 8
+	default:
+		return uint64(b[0]), 1, nil
+	}
+
+	if len(b) < 1+size {
+		return 0, 0, fmt.Errorf("malformed packet")
+	}
+
+	var n uint64
+	for i := size; i > 0; i-- {
+		n = n<<8 | uint64(b[i])
+	}
+	return n, 1 + size, nil
+}
+
+// readNulString returns the string and the bytes it took including the NUL
+func readNulString(b []byte) ([]byte, int, error) {
+	i := bytes.IndexByte(b, 0)
+	if i == -1 {
+		return nil, 0, fmt.Errorf("malformed packet")
+	}
+	return b[:i], i + 1, nil
+}
+
+func OKPacket(affected, insertId uint64, status, warnings uint16) []byte {
+	b := []byte{OK_PACKET}
+	b = appendLenEnc(b, affected)
+	b = appendLenEnc(b, insertId)
+	return append(b, byte(status), byte(status>>8), byte(warnings), byte(warnings>>8))
+}
+
+func ErrorPacket(code uint16, state, message string) []byte {
+	if len(state) != 5 {
+		state = "HY000"
+	}
+
+	b := []byte{ERR_PACKET, byte(code), byte(code >> 8), '#'}
+	b = append(b, state...)
+	return append(b, message...)
+}
+
+func IsOK(p []byte) bool {
+	return len(p) > 0 && p[0] == OK_PACKET
+}
+
+func IsErr(p []byte) bool {
+	return len(p) > 0 && p[0] == ERR_PACKET
+}
+
+// IsEOF tells the EOF packets from the rows starting with 0xfe
+func IsEOF(p []byte) bool {
+	return len(p) > 0 && len(p) < 9 && p[0] == EOF_PACKET
+}
+
+// Status returns the server status flags of an OK or EOF packet
+func Status(p []byte) (uint16, error) {
+	if IsEOF(p) {
+		if len(p) < 5 {
+			// pre 4.1 EOF
+			return 0, nil
+		}
+		return binary.LittleEndian.Uint16(p[3:]), nil
+	}
+
+	if !IsOK(p) && (len(p) == 0 || p[0] != EOF_PACKET) {
+		return 0, fmt.Errorf("not an OK packet")
+	}
+
+	pos := 1
+	for i := 0; i < 2; i++ {
+		_, n, err := readLenEnc(p[pos:])
+		if err != nil {
+			return 0, err
+		}
+		pos += n
+	}
+
+	if len(p) < pos+2 {
+		return 0, fmt.Errorf("malformed OK packet")
+	}
+	return binary.LittleEndian.Uint16(p[pos:]), nil
+}
+
+// Error is an ERR packet from the server
+type Error struct {
+	Code    uint16
+	State   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ERROR %d (%s): %s", e.Code, e.State, e.Message)
+}
+
+func (e *Error) Packet() []byte {
+	return ErrorPacket(e.Code, e.State, e.Message)
+}
+
+func ParseError(p []byte) *Error {
+	e := &Error{State: "HY000"}
+	if len(p) < 3 {
+		e.Message = "malformed error packet"
+		return e
+	}
+
+	e.Code = binary.LittleEndian.Uint16(p[1:])
+	msg := p[3:]
+	if len(msg) >= 6 && msg[0] == '#' {
+		e.State = string(msg[1:6])
+		msg = msg[6:]
+	}
+	e.Message = string(msg)
+	return e
+}
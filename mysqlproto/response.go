@@ -0,0 +1,122 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package mysqlproto
+
+import (
+	"fmt"
+)
+
+// ReadResult reads the whole response of a COM_QUERY and passes every
+// packet to relay. It returns the server status of the last OK or EOF
+// packet, known is false if the response ended with an ERR packet.
+func (c *Conn) ReadResult(relay func([]byte) error) (status uint16, known bool, err error) {
+	for {
+		status, known, err = c.readResultSet(relay)
+		if err != nil || !known || status&SERVER_MORE_RESULTS_EXISTS == 0 {
+			return
+		}
+	}
+}
+
+func (c *Conn) readResultSet(relay func([]byte) error) (uint16, bool, error) {
+	p, err := c.readRelay(relay)
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch {
+	case IsOK(p):
+		status, err := Status(p)
+		return status, err == nil, err
+	case IsErr(p):
+		return 0, false, nil
+	case p[0] == LOCAL_INFILE:
+		return 0, false, fmt.Errorf("LOAD DATA LOCAL is not supported")
+	}
+
+	columns, _, err := readLenEnc(p)
+	if err != nil {
+		return 0, false, err
+	}
+
+	for i := uint64(0); i < columns; i++ {
+		if _, err := c.readRelay(relay); err != nil {
+			return 0, false, err
+		}
+	}
+
+	deprecateEOF := c.Capabilities&CLIENT_DEPRECATE_EOF != 0
+	if !deprecateEOF {
+		if _, err := c.readRelay(relay); err != nil {
+			return 0, false, err
+		}
+	}
+
+	// the rows
+	for {
+		p, err := c.readRelay(relay)
+		if err != nil {
+			return 0, false, err
+		}
+
+		switch {
+		case IsErr(p):
+			return 0, false, nil
+		case IsEOF(p):
+			status, err := Status(p)
+			return status, err == nil, err
+		case deprecateEOF && p[0] == EOF_PACKET && len(p) < MaxPacketSize:
+			status, err := Status(p)
+			return status, err == nil, err
+		}
+	}
+}
+
+// ReadFieldList reads the response of COM_FIELD_LIST
+func (c *Conn) ReadFieldList(relay func([]byte) error) error {
+	for {
+		p, err := c.readRelay(relay)
+		if err != nil {
+			return err
+		}
+
+		if IsErr(p) || IsEOF(p) || (c.Capabilities&CLIENT_DEPRECATE_EOF != 0 && p[0] == EOF_PACKET) {
+			return nil
+		}
+	}
+}
+
+// ReadSimple reads the single packet response of the commands like
+// COM_PING and COM_STATISTICS
+func (c *Conn) ReadSimple(relay func([]byte) error) (uint16, bool, error) {
+	p, err := c.readRelay(relay)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if IsOK(p) {
+		status, err := Status(p)
+		return status, err == nil, err
+	}
+	return 0, false, nil
+}
+
+func (c *Conn) readRelay(relay func([]byte) error) ([]byte, error) {
+	p, err := c.ReadPacket()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p) == 0 {
+		return nil, fmt.Errorf("empty packet in response")
+	}
+
+	if relay != nil {
+		if err := relay(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
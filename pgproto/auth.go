@@ -0,0 +1,114 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package pgproto
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// Login is what we connect to a server with, the database defaults to the
+// name of the user on the server side
+type Login struct {
+	User     string
+	Password string
+	Database string
+}
+
+// Connect sends the startup message and authenticates with the password,
+// it returns once the server is ready for the queries
+func Connect(conn *Conn, login *Login) error {
+	p := make([]byte, 4)
+	binary.BigEndian.PutUint32(p, ProtocolVersion)
+	p = appendString(appendString(p, "user"), login.User)
+	if login.Database != "" {
+		p = appendString(appendString(p, "database"), login.Database)
+	}
+	p = appendString(appendString(p, "application_name"), "gbalancer")
+	p = append(p, 0)
+
+	if err := conn.WriteMessage(0, p); err != nil {
+		return err
+	}
+
+	var scram *scramClient
+	for {
+		typ, payload, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		switch typ {
+		case MsgAuthentication:
+			if len(payload) < 4 {
+				return fmt.Errorf("malformed authentication request")
+			}
+			request, data := binary.BigEndian.Uint32(payload), payload[4:]
+
+			switch request {
+			case AuthOK:
+			case AuthCleartext:
+				err = conn.WriteMessage(MsgPassword, appendString(nil, login.Password))
+			case AuthMD5:
+				if len(data) < 4 {
+					return fmt.Errorf("malformed md5 salt")
+				}
+				err = conn.WriteMessage(MsgPassword, appendString(nil, md5Password(login.User, login.Password, data[:4])))
+			case AuthSASL:
+				if !bytes.Contains(data, []byte(ScramSHA256+"\x00")) {
+					return fmt.Errorf("no supported SASL mechanism in %q", data)
+				}
+				if scram, err = newScramClient("", login.Password); err != nil {
+					return err
+				}
+				first := scram.clientFirst()
+				msg := appendString(nil, ScramSHA256)
+				msg = append(msg, 0, 0, 0, 0)
+				binary.BigEndian.PutUint32(msg[len(msg)-4:], uint32(len(first)))
+				err = conn.WriteMessage(MsgPassword, append(msg, first...))
+			case AuthSASLContinue:
+				if scram == nil {
+					return fmt.Errorf("unexpected SASL continue")
+				}
+				var final string
+				if final, err = scram.clientFinal(string(data)); err == nil {
+					err = conn.WriteMessage(MsgPassword, []byte(final))
+				}
+			case AuthSASLFinal:
+				if scram == nil {
+					return fmt.Errorf("unexpected SASL final")
+				}
+				err = scram.verify(string(data))
+			default:
+				return fmt.Errorf("unsupported authentication %d", request)
+			}
+
+			if err != nil {
+				return err
+			}
+		case MsgParameterStatus:
+			name, n := readString(payload)
+			value, _ := readString(payload[n:])
+			conn.Params[name] = value
+		case MsgBackendKeyData, MsgNoticeResponse:
+		case MsgReadyForQuery:
+			return nil
+		case MsgErrorResponse:
+			return ParseError(payload)
+		default:
+			return fmt.Errorf("unexpected message %q in the startup", typ)
+		}
+	}
+}
+
+// md5Password is "md5" followed by md5(md5(password + user) + salt) in hex
+func md5Password(user, password string, salt []byte) string {
+	inner := md5.Sum([]byte(password + user))
+	outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+	return "md5" + hex.EncodeToString(outer[:])
+}
@@ -0,0 +1,111 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+// Package pgproto implements the client side of the PostgreSQL protocol
+// needed by the health checks: the startup, the password authentications
+// and the simple queries.
+package pgproto
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+// Conn reads and writes the messages of a connection to a server
+type Conn struct {
+	net.Conn
+	reader *bufio.Reader
+	// the parameters reported by the server
+	Params map[string]string
+}
+
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{Conn: conn, reader: bufio.NewReader(conn), Params: make(map[string]string)}
+}
+
+// ReadMessage returns the type and the payload of a message
+func (c *Conn) ReadMessage() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[1:])
+	if length < 4 || length > MaxMessageSize {
+		return 0, nil, fmt.Errorf("invalid message length %d", length)
+	}
+
+	payload := make([]byte, length-4)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// WriteMessage sends a message, the startup one goes without a type
+func (c *Conn) WriteMessage(typ byte, payload []byte) error {
+	data := make([]byte, 5+len(payload))
+	data[0] = typ
+	binary.BigEndian.PutUint32(data[1:], uint32(len(payload)+4))
+	copy(data[5:], payload)
+
+	if typ == 0 {
+		data = data[1:]
+	}
+	_, err := c.Write(data)
+	return err
+}
+
+// Terminate tells the server we're leaving and closes the connection
+func (c *Conn) Terminate() error {
+	c.WriteMessage(MsgTerminate, nil)
+	return c.Close()
+}
+
+// Error is an ErrorResponse from the server
+type Error struct {
+	Severity string
+	Code     string
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Severity, e.Code, e.Message)
+}
+
+func ParseError(p []byte) *Error {
+	e := &Error{}
+	for len(p) > 1 {
+		field := p[0]
+		value, n := readString(p[1:])
+		p = p[1+n:]
+
+		switch field {
+		case 'S':
+			e.Severity = value
+		case 'C':
+			e.Code = value
+		case 'M':
+			e.Message = value
+		}
+	}
+	return e
+}
+
+// readString returns a null terminated string and the bytes it took
+func readString(p []byte) (string, int) {
+	for i, b := range p {
+		if b == 0 {
+			return string(p[:i]), i + 1
+		}
+	}
+	return string(p), len(p)
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(append(b, s...), 0)
+}
@@ -0,0 +1,39 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package pgproto
+
+const (
+	ProtocolVersion uint32 = 3 << 16
+	MaxMessageSize  uint32 = 1 << 30
+)
+
+// message types
+const (
+	MsgAuthentication  byte = 'R'
+	MsgParameterStatus byte = 'S'
+	MsgBackendKeyData  byte = 'K'
+	MsgReadyForQuery   byte = 'Z'
+	MsgRowDescription  byte = 'T'
+	MsgDataRow         byte = 'D'
+	MsgCommandComplete byte = 'C'
+	MsgEmptyQuery      byte = 'I'
+	MsgErrorResponse   byte = 'E'
+	MsgNoticeResponse  byte = 'N'
+	MsgPassword        byte = 'p'
+	MsgQuery           byte = 'Q'
+	MsgTerminate       byte = 'X'
+)
+
+// authentication requests
+const (
+	AuthOK           uint32 = 0
+	AuthCleartext    uint32 = 3
+	AuthMD5          uint32 = 5
+	AuthSASL         uint32 = 10
+	AuthSASLContinue uint32 = 11
+	AuthSASLFinal    uint32 = 12
+)
+
+const ScramSHA256 = "SCRAM-SHA-256"
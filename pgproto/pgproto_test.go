@@ -0,0 +1,97 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package pgproto
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+)
+
+// the example exchange of RFC 7677
+func TestScram(t *testing.T) {
+	s := &scramClient{user: "user", password: "pencil", nonce: "rOprNGfwEbeRWgbNEkqO"}
+	if first := s.clientFirst(); first != "n,,n=user,r=rOprNGfwEbeRWgbNEkqO" {
+		t.Fatalf("client first %s", first)
+	}
+
+	final, err := s.clientFinal("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="; final != expected {
+		t.Fatalf("client final %s", final)
+	}
+
+	if err := s.verify("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.verify("v=AAAA"); err == nil {
+		t.Fatal("accepted a wrong server signature")
+	}
+}
+
+func authRequest(request uint32, data ...byte) []byte {
+	p := make([]byte, 4)
+	binary.BigEndian.PutUint32(p, request)
+	return append(p, data...)
+}
+
+func dataRow(values ...string) []byte {
+	p := []byte{0, byte(len(values))}
+	for _, v := range values {
+		length := make([]byte, 4)
+		binary.BigEndian.PutUint32(length, uint32(len(v)))
+		p = append(append(p, length...), v...)
+	}
+	return p
+}
+
+func TestConnectQuery(t *testing.T) {
+	c1, c2 := net.Pipe()
+	server, client := NewConn(c1), NewConn(c2)
+	salt := []byte{1, 2, 3, 4}
+
+	go func() {
+		// the startup message has no type
+		var header [4]byte
+		io.ReadFull(server.reader, header[:])
+		startup := make([]byte, binary.BigEndian.Uint32(header[:])-4)
+		io.ReadFull(server.reader, startup)
+
+		server.WriteMessage(MsgAuthentication, authRequest(AuthMD5, salt...))
+		_, password, _ := server.ReadMessage()
+		if p, _ := readString(password); p != md5Password("app", "secret", salt) {
+			server.WriteMessage(MsgErrorResponse, []byte("SFATAL\x00C28P01\x00Mpassword authentication failed\x00\x00"))
+			return
+		}
+
+		server.WriteMessage(MsgAuthentication, authRequest(AuthOK))
+		server.WriteMessage(MsgParameterStatus, []byte("server_version\x0016.2\x00"))
+		server.WriteMessage(MsgReadyForQuery, []byte{'I'})
+
+		server.ReadMessage()
+		server.WriteMessage(MsgRowDescription, []byte{0, 2})
+		server.WriteMessage(MsgDataRow, dataRow("t", "1.5"))
+		server.WriteMessage(MsgCommandComplete, []byte("SELECT 1\x00"))
+		server.WriteMessage(MsgReadyForQuery, []byte{'I'})
+	}()
+
+	if err := Connect(client, &Login{User: "app", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if v := client.Params["server_version"]; v != "16.2" {
+		t.Fatalf("server version %s", v)
+	}
+
+	rows, err := client.Query("SELECT pg_is_in_recovery(), 1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0][0] != "t" || rows[0][1] != "1.5" {
+		t.Fatalf("unexpected rows %q", rows)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package pgproto
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Query runs a simple query and returns the rows of the last statement in
+// the text format, NULL is returned as an empty string
+func (c *Conn) Query(q string) ([][]string, error) {
+	if err := c.WriteMessage(MsgQuery, appendString(nil, q)); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	var queryErr error
+	for {
+		typ, payload, err := c.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		switch typ {
+		case MsgRowDescription:
+			rows = nil
+		case MsgDataRow:
+			row, err := parseDataRow(payload)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		case MsgCommandComplete, MsgEmptyQuery, MsgNoticeResponse, MsgParameterStatus:
+		case MsgErrorResponse:
+			queryErr = ParseError(payload)
+		case MsgReadyForQuery:
+			if queryErr != nil {
+				return nil, queryErr
+			}
+			return rows, nil
+		default:
+			return nil, fmt.Errorf("unexpected message %q in the query response", typ)
+		}
+	}
+}
+
+func parseDataRow(p []byte) ([]string, error) {
+	if len(p) < 2 {
+		return nil, fmt.Errorf("malformed data row")
+	}
+
+	count := int(binary.BigEndian.Uint16(p))
+	p = p[2:]
+
+	row := make([]string, count)
+	for i := range row {
+		if len(p) < 4 {
+			return nil, fmt.Errorf("malformed data row")
+		}
+		length := int32(binary.BigEndian.Uint32(p))
+		p = p[4:]
+
+		// -1 is NULL
+		if length < 0 {
+			continue
+		}
+		if len(p) < int(length) {
+			return nil, fmt.Errorf("malformed data row")
+		}
+		row[i] = string(p[:length])
+		p = p[length:]
+	}
+	return row, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package pgproto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// scramClient runs the SCRAM-SHA-256 exchange of RFC 7677, the server
+// ignores the user name in it so it's usually empty
+type scramClient struct {
+	user      string
+	password  string
+	nonce     string
+	firstBare string
+	serverSig []byte
+}
+
+func newScramClient(user, password string) (*scramClient, error) {
+	nonce := make([]byte, 18)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &scramClient{user: user, password: password, nonce: base64.StdEncoding.EncodeToString(nonce)}, nil
+}
+
+func (s *scramClient) clientFirst() string {
+	user := strings.NewReplacer("=", "=3D", ",", "=2C").Replace(s.user)
+	s.firstBare = "n=" + user + ",r=" + s.nonce
+	return "n,," + s.firstBare
+}
+
+// clientFinal answers the server-first-message with the proof
+func (s *scramClient) clientFinal(serverFirst string) (string, error) {
+	var nonce, salt string
+	var iterations int
+	for _, attr := range strings.Split(serverFirst, ",") {
+		if len(attr) < 2 || attr[1] != '=' {
+			continue
+		}
+		switch attr[0] {
+		case 'r':
+			nonce = attr[2:]
+		case 's':
+			salt = attr[2:]
+		case 'i':
+			iterations, _ = strconv.Atoi(attr[2:])
+		}
+	}
+
+	if !strings.HasPrefix(nonce, s.nonce) || len(nonce) == len(s.nonce) {
+		return "", fmt.Errorf("invalid SCRAM server nonce")
+	}
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil || iterations <= 0 {
+		return "", fmt.Errorf("invalid SCRAM salt or iterations in %q", serverFirst)
+	}
+
+	salted := pbkdf2([]byte(s.password), saltBytes, iterations)
+	clientKey := hmacSum(salted, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+
+	finalBare := "c=biws,r=" + nonce
+	authMessage := []byte(s.firstBare + "," + serverFirst + "," + finalBare)
+
+	proof := hmacSum(storedKey[:], authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	s.serverSig = hmacSum(hmacSum(salted, []byte("Server Key")), authMessage)
+
+	return finalBare + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// verify checks the signature of the server-final-message
+func (s *scramClient) verify(serverFinal string) error {
+	if strings.HasPrefix(serverFinal, "e=") {
+		return fmt.Errorf("SCRAM authentication failed: %s", serverFinal[2:])
+	}
+	if !strings.HasPrefix(serverFinal, "v=") {
+		return fmt.Errorf("invalid SCRAM server final message")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(serverFinal[2:])
+	if err != nil || subtle.ConstantTimeCompare(sig, s.serverSig) != 1 {
+		return fmt.Errorf("invalid SCRAM server signature")
+	}
+	return nil
+}
+
+func hmacSum(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// pbkdf2 derives a key of one block, the size of the SCRAM-SHA-256 one
+func pbkdf2(password, salt []byte, iterations int) []byte {
+	u := hmacSum(password, append(append([]byte{}, salt...), 0, 0, 0, 1))
+	key := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		u = hmacSum(password, u)
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
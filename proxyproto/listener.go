@@ -0,0 +1,95 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package proxyproto
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// how long we wait for the header of a new connection
+	HeaderTimeout = 5 * time.Second
+)
+
+// Listener expects a PROXY header on every accepted connection, the
+// header is read on the first use of the connection so a slow client
+// never blocks Accept
+type Listener struct {
+	net.Listener
+}
+
+func NewListener(l net.Listener) *Listener {
+	return &Listener{l}
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{Conn: conn, reader: bufio.NewReader(conn), ready: make(chan struct{})}, nil
+}
+
+// Conn reports the addresses from the PROXY header, a connection with a
+// bad header fails on the first read
+type Conn struct {
+	net.Conn
+	reader *bufio.Reader
+	once   sync.Once
+	ready  chan struct{} // closed once the header got parsed
+	header *Header
+	err    error
+}
+
+func (c *Conn) readHeader() {
+	c.once.Do(func() {
+		c.Conn.SetReadDeadline(time.Now().Add(HeaderTimeout))
+		c.header, c.err = Read(c.reader)
+		c.Conn.SetReadDeadline(time.Time{})
+		close(c.ready)
+	})
+}
+
+// Header reads the PROXY header if it's not yet received
+func (c *Conn) Header() (*Header, error) {
+	c.readHeader()
+	return c.header, c.err
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+// received returns the header if it's already parsed, the addresses never
+// block on the header
+func (c *Conn) received() *Header {
+	select {
+	case <-c.ready:
+		return c.header
+	default:
+		return nil
+	}
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	if h := c.received(); h != nil && h.Source != nil {
+		return h.Source
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	if h := c.received(); h != nil && h.Destination != nil {
+		return h.Destination
+	}
+	return c.Conn.LocalAddr()
+}
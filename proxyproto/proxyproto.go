@@ -0,0 +1,300 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+// Package proxyproto implements the v1 and v2 PROXY protocol headers of
+// haproxy, which pass the original client address to the server.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the longest v1 header including the CRLF
+	v1MaxLength = 107
+)
+
+// the stream headers to pass the PROXY header over a tunnel
+const (
+	HTTPVersion     = "Proxy-Version"
+	HTTPSource      = "Proxy-Source"
+	HTTPDestination = "Proxy-Destination"
+)
+
+var (
+	v1Prefix    = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// Header is the connection information carried by the PROXY protocol, the
+// addresses are nil for the connections the protocol can't describe,
+// e.g. the unix ones or the health checks of the upstream balancer
+type Header struct {
+	Version     int
+	Source      *net.TCPAddr
+	Destination *net.TCPAddr
+}
+
+// NewHeader describes a connection from src to dst, the addresses other
+// than tcp ones are sent as unknown
+func NewHeader(version int, src, dst net.Addr) *Header {
+	h := &Header{Version: version}
+
+	s, sok := src.(*net.TCPAddr)
+	d, dok := dst.(*net.TCPAddr)
+	if sok && dok && (s.IP.To4() == nil) == (d.IP.To4() == nil) {
+		h.Source, h.Destination = s, d
+	}
+	return h
+}
+
+// ParseVersion accepts "v1", "v2" and "off"
+func ParseVersion(v string) (int, error) {
+	switch strings.ToLower(v) {
+	case "", "off", "none":
+		return 0, nil
+	case "1", "v1":
+		return 1, nil
+	case "2", "v2":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("unknown PROXY protocol version %q", v)
+}
+
+func (h *Header) tcp4() bool {
+	return h.Source.IP.To4() != nil
+}
+
+// Format returns the header in its wire format
+func (h *Header) Format() ([]byte, error) {
+	switch h.Version {
+	case 1:
+		return h.formatV1(), nil
+	case 2:
+		return h.formatV2(), nil
+	}
+	return nil, fmt.Errorf("unknown PROXY protocol version %d", h.Version)
+}
+
+func (h *Header) formatV1() []byte {
+	if h.Source == nil {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+
+	proto := "TCP6"
+	if h.tcp4() {
+		proto = "TCP4"
+	}
+	return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n", proto,
+		h.Source.IP, h.Destination.IP, h.Source.Port, h.Destination.Port))
+}
+
+func (h *Header) formatV2() []byte {
+	var buf bytes.Buffer
+	buf.Write(v2Signature)
+
+	if h.Source == nil {
+		// LOCAL command, UNSPEC family
+		buf.Write([]byte{0x20, 0x00, 0x00, 0x00})
+		return buf.Bytes()
+	}
+
+	var addrs []byte
+	if h.tcp4() {
+		buf.Write([]byte{0x21, 0x11})
+		addrs = append(addrs, h.Source.IP.To4()...)
+		addrs = append(addrs, h.Destination.IP.To4()...)
+	} else {
+		buf.Write([]byte{0x21, 0x21})
+		addrs = append(addrs, h.Source.IP.To16()...)
+		addrs = append(addrs, h.Destination.IP.To16()...)
+	}
+
+	ports := make([]byte, 4)
+	binary.BigEndian.PutUint16(ports, uint16(h.Source.Port))
+	binary.BigEndian.PutUint16(ports[2:], uint16(h.Destination.Port))
+	addrs = append(addrs, ports...)
+
+	binary.Write(&buf, binary.BigEndian, uint16(len(addrs)))
+	buf.Write(addrs)
+	return buf.Bytes()
+}
+
+// WriteTo sends the header ahead of the proxied data
+func (h *Header) WriteTo(w io.Writer) (int64, error) {
+	data, err := h.Format()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Read parses a v1 or v2 header from the start of the stream
+func Read(r *bufio.Reader) (*Header, error) {
+	// tell the versions by the first byte, so a client without the header
+	// fails without waiting for more data
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	var sig []byte
+	switch first[0] {
+	case v1Prefix[0]:
+		if sig, err = r.Peek(len(v1Prefix)); err == nil && bytes.Equal(sig, v1Prefix) {
+			return readV1(r)
+		}
+	case v2Signature[0]:
+		if sig, err = r.Peek(len(v2Signature)); err == nil && bytes.Equal(sig, v2Signature) {
+			return readV2(r)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("no PROXY protocol header")
+}
+
+func readV1(r *bufio.Reader) (*Header, error) {
+	var line []byte
+	for len(line) < v1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, fmt.Errorf("bad PROXY v1 header %q", line)
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	h := &Header{Version: 1}
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return h, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("bad PROXY v1 header %q", line)
+	}
+
+	var err error
+	if h.Source, err = parseTCPAddr(fields[2], fields[4]); err != nil {
+		return nil, err
+	}
+	if h.Destination, err = parseTCPAddr(fields[3], fields[5]); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func parseTCPAddr(ip, port string) (*net.TCPAddr, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil, fmt.Errorf("bad address %q in PROXY header", ip)
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("bad port %q in PROXY header", port)
+	}
+	return &net.TCPAddr{IP: addr, Port: int(p)}, nil
+}
+
+func readV2(r *bufio.Reader) (*Header, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, err
+	}
+
+	if fixed[12]>>4 != 2 {
+		return nil, fmt.Errorf("bad PROXY v2 version %d", fixed[12]>>4)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(fixed[14:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	h := &Header{Version: 2}
+
+	// LOCAL command, the connection is made by the proxy itself
+	if fixed[12]&0xf == 0 {
+		return h, nil
+	}
+
+	var size int
+	switch fixed[13] >> 4 {
+	case 1:
+		size = net.IPv4len
+	case 2:
+		size = net.IPv6len
+	default:
+		// unix or unspecified, keep the connection addresses
+		return h, nil
+	}
+
+	if len(payload) < 2*size+4 {
+		return nil, fmt.Errorf("short PROXY v2 address block")
+	}
+
+	src := net.IP(payload[:size])
+	dst := net.IP(payload[size : 2*size])
+	ports := payload[2*size:]
+	h.Source = &net.TCPAddr{IP: src, Port: int(binary.BigEndian.Uint16(ports))}
+	h.Destination = &net.TCPAddr{IP: dst, Port: int(binary.BigEndian.Uint16(ports[2:]))}
+	return h, nil
+}
+
+// HTTPHeader encodes the header as the headers of a tunnel stream, the
+// other end of the tunnel sends it to the service
+func (h *Header) HTTPHeader() http.Header {
+	header := http.Header{}
+	header.Set(HTTPVersion, strconv.Itoa(h.Version))
+	if h.Source != nil {
+		header.Set(HTTPSource, h.Source.String())
+		header.Set(HTTPDestination, h.Destination.String())
+	}
+	return header
+}
+
+// FromHTTPHeader decodes the header passed with a tunnel stream, it
+// returns nil if there's none
+func FromHTTPHeader(header http.Header) (*Header, error) {
+	v := header.Get(HTTPVersion)
+	if v == "" {
+		return nil, nil
+	}
+
+	version, err := ParseVersion(v)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Header{Version: version}
+	if src := header.Get(HTTPSource); src != "" {
+		if h.Source, err = net.ResolveTCPAddr("tcp", src); err != nil {
+			return nil, err
+		}
+		if h.Destination, err = net.ResolveTCPAddr("tcp", header.Get(HTTPDestination)); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
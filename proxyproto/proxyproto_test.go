@@ -0,0 +1,116 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+func tcpAddr(t *testing.T, addr string) *net.TCPAddr {
+	a, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestFormatV1(t *testing.T) {
+	h := NewHeader(1, tcpAddr(t, "192.168.0.1:56324"), tcpAddr(t, "192.168.0.11:3306"))
+	data, _ := h.Format()
+	if string(data) != "PROXY TCP4 192.168.0.1 192.168.0.11 56324 3306\r\n" {
+		t.Errorf("unexpected header %q", data)
+	}
+
+	h = NewHeader(1, &net.UnixAddr{Name: "@", Net: "unix"}, &net.UnixAddr{Name: "/tmp/s", Net: "unix"})
+	data, _ = h.Format()
+	if string(data) != "PROXY UNKNOWN\r\n" {
+		t.Errorf("unexpected header %q", data)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	headers := []*Header{
+		NewHeader(1, tcpAddr(t, "10.0.0.1:1234"), tcpAddr(t, "10.0.0.2:3306")),
+		NewHeader(1, tcpAddr(t, "[2001:db8::1]:1234"), tcpAddr(t, "[2001:db8::2]:3306")),
+		NewHeader(2, tcpAddr(t, "10.0.0.1:1234"), tcpAddr(t, "10.0.0.2:3306")),
+		NewHeader(2, tcpAddr(t, "[2001:db8::1]:1234"), tcpAddr(t, "[2001:db8::2]:3306")),
+		NewHeader(2, nil, nil),
+	}
+
+	for _, h := range headers {
+		data, err := h.Format()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := bufio.NewReader(bytes.NewReader(append(data, "payload"...)))
+		got, err := Read(r)
+		if err != nil {
+			t.Fatalf("v%d %q: %s", h.Version, data, err)
+		}
+
+		if got.Version != h.Version || (got.Source == nil) != (h.Source == nil) {
+			t.Fatalf("got %+v, expected %+v", got, h)
+		}
+		if h.Source != nil && (got.Source.String() != h.Source.String() || got.Destination.String() != h.Destination.String()) {
+			t.Errorf("got %v -> %v, expected %v -> %v", got.Source, got.Destination, h.Source, h.Destination)
+		}
+
+		if rest, _ := ioutil.ReadAll(r); string(rest) != "payload" {
+			t.Errorf("payload got consumed: %q", rest)
+		}
+	}
+}
+
+func TestBadHeader(t *testing.T) {
+	for _, data := range []string{
+		"GET / HTTP/1.0\r\n\r\n",
+		"PROXY TCP4 10.0.0.1 10.0.0.2 1234\r\n",
+		"PROXY TCP4 10.0.0.1 10.0.0.2 1234 99999\r\n",
+	} {
+		if _, err := Read(bufio.NewReader(bytes.NewBufferString(data))); err == nil {
+			t.Errorf("%q accepted", data)
+		}
+	}
+}
+
+func TestListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := NewListener(l)
+	defer pl.Close()
+
+	go func() {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("PROXY TCP4 10.0.0.1 10.0.0.2 1234 3306\r\nhello"))
+		conn.Close()
+	}()
+
+	conn, err := pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	data, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("unexpected data %q", data)
+	}
+	if conn.RemoteAddr().String() != "10.0.0.1:1234" || conn.LocalAddr().String() != "10.0.0.2:3306" {
+		t.Errorf("unexpected addresses %v -> %v", conn.RemoteAddr(), conn.LocalAddr())
+	}
+}
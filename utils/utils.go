@@ -17,7 +17,7 @@ import (
 )
 
 var (
-	log = logger.NewLogger()
+	log = logger.New("utils")
 )
 
 func RunCommand(cmd string) error {
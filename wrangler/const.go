@@ -12,10 +12,55 @@ const (
 const (
 	WsrepAddresses = "wsrep_incoming_addresses"
 	WsrepConnected = "wsrep_connected"
+	WsrepStatus    = "wsrep_cluster_status"
+	WsrepUUID      = "wsrep_cluster_state_uuid"
+	WsrepSize      = "wsrep_cluster_size"
+	WsrepNodeName  = "wsrep_node_name"
+	WsrepNodeAddr  = "wsrep_node_incoming_address"
 	CheckInterval  = 60
+	FailInterval   = 5
 )
 
+// defaults of the http check
 const (
-	FlagDown int = 0
-	FlagUp   int = 1
+	HTTPStatus  = "200-399"
+	HTTPTimeout = 5
+	MaxHTTPBody = 64 << 10
+)
+
+// the lag allowed for the replicas, in seconds
+const (
+	ReplicaMaxLag = 30
+)
+
+// defaults of the postgres check
+const (
+	PostgresDatabase = "postgres"
+	PostgresTimeout  = 5
+)
+
+const (
+	RedisTimeout = 5
+)
+
+// the up backends with a role are flagged with it, the draining ones get
+// FlagDrain and the weight goes above WeightShift, 0 for the default one
+const (
+	FlagDown    int = 0
+	FlagUp      int = 1
+	FlagPrimary int = 2
+	FlagReplica int = 3
+	RoleMask    int = 0xff
+	FlagDrain   int = 1 << 8
+	WeightShift     = 16
+)
+
+const (
+	DefaultWeight = 100
+	MaxWeight     = 10000
+)
+
+// the time an ext check may run, in seconds
+const (
+	ExtTimeout = 10
 )
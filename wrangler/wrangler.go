@@ -5,53 +5,258 @@
 package wrangler
 
 import (
+	"fmt"
 	"github.com/zhgwenming/gbalancer/config"
 	logger "github.com/zhgwenming/gbalancer/log"
-	"os"
+	"sync"
 	"time"
 )
 
 var (
-	log = logger.NewLogger()
+	log = logger.New("wrangler")
 )
 
 type healthDriver interface {
 	AddDirector(backend string) error
 	BuildActiveBackends() (map[string]int, error)
+	ProbeErrors() map[string]error
 }
 
 type Wrangler struct {
 	healthExec healthDriver
 	Backends   map[string]int
-	BackChan   chan<- map[string]int
+	BackChan   chan<- map[string]int // the changed backends, FlagDown for the gone ones
+	sent       map[string]int        // the backends the engines got
+	config     *config.Configuration
+	updates    chan *config.Configuration
+	mutex      sync.Mutex
+	status     map[string]error
+	sdStatus   string
+	ready      bool
+	// the check schedule of the backends up and down
+	interval     time.Duration
+	failInterval time.Duration
+	// Notify reports the readiness and the backend counts to the service
+	// manager, nil if nobody cares
+	Notify func(state string) error
 }
 
-func NewWrangler(config *config.Configuration, back chan<- map[string]int) *Wrangler {
+func newHealthDriver(config *config.Configuration) (healthDriver, error) {
+	if len(config.Check) > 0 {
+		return newHealthMapped(config)
+	}
+
+	hexec, err := newDriver(config.Service, config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range config.Backend {
+		hexec.AddDirector(b)
+	}
+	return hexec, nil
+}
+
+// newHealthMapped sets up the checks on the check addresses of the backends
+func newHealthMapped(config *config.Configuration) (healthDriver, error) {
+	h := NewHealthMapped()
+	for _, b := range config.Backend {
+		addr, typ, err := config.CheckTarget(b)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := h.drivers[typ]; !ok {
+			driver, err := newDriver(typ, config)
+			if err != nil {
+				return nil, err
+			}
+			h.AddCheck(typ, driver)
+		}
+
+		if err := h.AddBackend(b, addr, typ); err != nil {
+			return nil, err
+		}
+		if addr != b || typ != config.Service {
+			log.Debugf("wrangler: checking %s with %s on %s", b, typ, addr)
+		}
+	}
+	return h, nil
+}
+
+// FlagString describes the state of an up backend
+func FlagString(flag int) string {
+	var state string
+	switch flag & RoleMask {
+	case FlagPrimary:
+		state = "the primary"
+	case FlagReplica:
+		state = "a replica"
+	default:
+		state = "up"
+	}
+
+	if flag&FlagDrain != 0 {
+		state += ", draining"
+	}
+	if weight := flag >> WeightShift; weight != 0 {
+		state += fmt.Sprintf(", weight %d", weight)
+	}
+	return state
+}
+
+func newDriver(service string, config *config.Configuration) (healthDriver, error) {
 	var hexec healthDriver
-	switch config.Service {
+	var err error
+	switch service {
 	case "galera":
-		hexec = NewGalera(config.User, config.Pass)
+		if hexec, err = NewGalera(config.User, config.Pass, &config.Galera); err != nil {
+			return nil, err
+		}
 	case "tcp":
 		hexec = NewHealthTcp()
 	case "http":
-		hexec = NewHealthHTTP()
+		if hexec, err = NewHealthHTTP(&config.HTTPCheck); err != nil {
+			return nil, err
+		}
+	case "mysql-replica":
+		if hexec, err = NewMySQLReplica(config.User, config.Pass, &config.Replica); err != nil {
+			return nil, err
+		}
+	case "postgres":
+		if hexec, err = NewPostgres(config.User, config.Pass, &config.Replica); err != nil {
+			return nil, err
+		}
+	case "redis":
+		if hexec, err = NewRedis(config.User, config.Pass, &config.Replica); err != nil {
+			return nil, err
+		}
 	case "ext":
-		hexec = NewHealthExt(config.ExtCommand)
 		if config.ExtCommand == "" {
-			log.Printf("Need to specify ExtCommand for ext Service")
-			os.Exit(1)
+			return nil, fmt.Errorf("Need to specify ExtCommand for ext Service")
+		}
+		if hexec, err = NewHealthExt(config.Service, config.ExtCommand, &config.Ext); err != nil {
+			return nil, err
 		}
 	default:
-		log.Printf("Unknown healthy monitor: %s", config.Service)
-		os.Exit(1)
+		return nil, fmt.Errorf("Unknown healthy monitor: %s", service)
+	}
+	return hexec, nil
+}
+
+// intervals returns the check intervals of the backends up and down
+func intervals(settings *config.HealthConfig) (time.Duration, time.Duration, error) {
+	interval := CheckInterval * time.Second
+	failInterval := FailInterval * time.Second
+
+	var err error
+	if settings.Interval != "" {
+		if interval, err = time.ParseDuration(settings.Interval); err != nil || interval <= 0 {
+			return 0, 0, fmt.Errorf("invalid Health.Interval %s", settings.Interval)
+		}
+	}
+	if settings.FailInterval != "" {
+		if failInterval, err = time.ParseDuration(settings.FailInterval); err != nil || failInterval <= 0 {
+			return 0, 0, fmt.Errorf("invalid Health.FailInterval %s", settings.FailInterval)
+		}
+	}
+	return interval, failInterval, nil
+}
+
+// NewWrangler returns an error if the service or the health settings are invalid
+func NewWrangler(settings *config.Configuration, back chan<- map[string]int) (*Wrangler, error) {
+	hexec, err := newHealthDriver(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	interval, failInterval, err := intervals(&settings.Health)
+	if err != nil {
+		return nil, err
 	}
 
 	backends := make(map[string]int, MaxBackends)
-	w := &Wrangler{hexec, backends, back}
-	for _, b := range config.Backend {
-		hexec.AddDirector(b)
+	updates := make(chan *config.Configuration, 1)
+	status := make(map[string]error, MaxBackends)
+	sent := make(map[string]int, MaxBackends)
+	w := &Wrangler{hexec, backends, back, sent, settings, updates, sync.Mutex{}, status, "", false, interval, failInterval, nil}
+	return w, nil
+}
+
+// UpdateConfig replaces the service settings and the director list, the new
+// settings take effect in the Monitor goroutine with an immediate check
+func (w *Wrangler) UpdateConfig(settings *config.Configuration) {
+	w.updates <- settings
+}
+
+func (w *Wrangler) setConfig(settings *config.Configuration) {
+	hexec, err := newHealthDriver(settings)
+	if err == nil {
+		w.interval, w.failInterval, err = intervals(&settings.Health)
+	}
+	if err != nil {
+		log.Warnf("wrangler: ignored the new config - %s\n", err)
+		return
+	}
+
+	log.Printf("wrangler: %s service, director list updated to %v\n", settings.Service, settings.Backend)
+	w.config = settings
+	w.healthExec = hexec
+}
+
+// Status returns the result of the last check, nil error for the active backends
+func (w *Wrangler) Status() map[string]error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	status := make(map[string]error, len(w.status))
+	for b, err := range w.status {
+		status[b] = err
+	}
+	return status
+}
+
+func (w *Wrangler) updateStatus(backends map[string]int) {
+	status := make(map[string]error, MaxBackends)
+	for b, err := range w.healthExec.ProbeErrors() {
+		status[b] = err
+	}
+	for b := range backends {
+		status[b] = nil
+	}
+
+	w.mutex.Lock()
+	w.status = status
+	w.mutex.Unlock()
+
+	w.notifyStatus(len(backends), len(status))
+}
+
+// notifyStatus reports the backend counts to systemd on changes
+func (w *Wrangler) notifyStatus(up, total int) {
+	sdStatus := fmt.Sprintf("STATUS=%d of %d backends up", up, total)
+	if sdStatus != w.sdStatus {
+		w.sdStatus = sdStatus
+		w.notify(sdStatus)
+	}
+}
+
+// notifyReady tells systemd we're able to forward connections from now on
+func (w *Wrangler) notifyReady() {
+	if w.ready {
+		return
+	}
+	w.ready = true
+	w.notify("READY=1")
+}
+
+func (w *Wrangler) notify(state string) {
+	if w.Notify == nil {
+		return
+	}
+	if err := w.Notify(state); err != nil {
+		log.Warnf("sd_notify: %s\n", err)
 	}
-	return w
 }
 
 func (w *Wrangler) ValidBackends() {
@@ -61,45 +266,79 @@ func (w *Wrangler) ValidBackends() {
 		return
 	}
 
+	w.updateStatus(backends)
+
 	//log.Printf("backends is %v\n", backends)
 
 	// remove fail node from w.Backends first
 	for b := range w.Backends {
 		if _, ok := backends[b]; !ok {
 			delete(w.Backends, b)
-			log.Printf("wrangler: detected server %s is down\n", b)
+			log.Warnf("wrangler: detected server %s is down\n", b)
 		}
 	}
 
 	// add new backends
-	for b := range backends {
-		if _, ok := w.Backends[b]; !ok {
-			log.Printf("wrangler: detected server %s is up\n", b)
-			w.Backends[b] = backends[b]
+	passed := make([]string, 0, len(backends))
+	for b, flag := range backends {
+		passed = append(passed, b)
+		if old, ok := w.Backends[b]; !ok {
+			log.Printf("wrangler: detected server %s is %s\n", b, FlagString(flag))
+			w.Backends[b] = flag
+		} else if old != flag {
+			log.Printf("wrangler: detected server %s is now %s\n", b, FlagString(flag))
+			w.Backends[b] = flag
 		}
 	}
 
-	// full set of backends needed by the ipvs engine
-	if len(backends) > 0 {
-		w.BackChan <- backends
-	}
+	w.sendBackends(passed...)
 }
 
+// Monitor checks the backends until the process exits, every backend on
+// its own schedule if the driver is able to, otherwise all of them in rounds
 func (w *Wrangler) Monitor() {
+	for {
+		var settings *config.Configuration
+		if prober := proberOf(w.healthExec); prober != nil {
+			settings = w.monitorBackends(prober)
+		} else {
+			settings = w.monitorRounds()
+		}
+		w.setConfig(settings)
+	}
+}
+
+// monitorRounds checks all the backends at once, it returns the new
+// settings once the config is updated
+func (w *Wrangler) monitorRounds() *config.Configuration {
 	for {
 		w.ValidBackends()
 		if len(w.Backends) > 0 {
 			break
 		}
-		time.Sleep(1 * time.Second)
+
+		select {
+		case <-time.After(1 * time.Second):
+		case settings := <-w.updates:
+			return settings
+		}
 	}
-	// periodic check
-	ticker := time.NewTicker(CheckInterval * time.Second)
+
+	w.notifyReady()
+
+	// periodic check, sooner while some backends are down
 	for {
+		interval := w.interval
+		if len(w.healthExec.ProbeErrors()) > 0 {
+			interval = w.failInterval
+		}
+
 		select {
-		case <-ticker.C:
+		case <-time.After(jitter(interval)):
 			//log.Printf("got a tick")
 			w.ValidBackends()
+		case settings := <-w.updates:
+			return settings
 		}
 	}
 }
@@ -0,0 +1,142 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"fmt"
+)
+
+// HealthMapped checks the backends on their check addresses with the
+// driver of each one, and reports the service addresses
+type HealthMapped struct {
+	drivers map[string]healthDriver
+	// check address of each driver to the service addresses
+	services map[string]map[string][]string
+	// driver and check address of each service address
+	targets map[string]checkTarget
+	errors  map[string]error
+}
+
+type checkTarget struct {
+	typ  string
+	addr string
+}
+
+func NewHealthMapped() *HealthMapped {
+	drivers := make(map[string]healthDriver)
+	services := make(map[string]map[string][]string)
+	targets := make(map[string]checkTarget, MaxBackends)
+	errors := make(map[string]error, MaxBackends)
+	return &HealthMapped{drivers, services, targets, errors}
+}
+
+func (h *HealthMapped) AddCheck(typ string, driver healthDriver) {
+	h.drivers[typ] = driver
+	h.services[typ] = make(map[string][]string)
+}
+
+// AddBackend checks the backend on addr with the driver of typ
+func (h *HealthMapped) AddBackend(backend, addr, typ string) error {
+	driver, ok := h.drivers[typ]
+	if !ok {
+		return fmt.Errorf("no %s check for %s", typ, backend)
+	}
+
+	services := h.services[typ]
+	if _, ok := services[addr]; !ok {
+		driver.AddDirector(addr)
+	}
+	services[addr] = append(services[addr], backend)
+	h.targets[backend] = checkTarget{typ, addr}
+	return nil
+}
+
+// Probe checks a backend on its check address
+func (h *HealthMapped) Probe(backend string) (int, error) {
+	t, ok := h.targets[backend]
+	if !ok {
+		return FlagDown, fmt.Errorf("no check for %s", backend)
+	}
+
+	prober, ok := h.drivers[t.typ].(backendProber)
+	if !ok {
+		return FlagDown, fmt.Errorf("the %s check can't probe %s alone", t.typ, backend)
+	}
+	return prober.Probe(t.addr)
+}
+
+// probing tells whether all the drivers check the backends one by one
+func (h *HealthMapped) probing() bool {
+	for _, driver := range h.drivers {
+		if _, ok := driver.(backendProber); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *HealthMapped) AddDirector(backend string) error {
+	return fmt.Errorf("backends of the mapped checks are added with AddBackend")
+}
+
+// the probe errors of the last check
+func (h *HealthMapped) ProbeErrors() map[string]error {
+	return h.errors
+}
+
+// serviceAddrs maps a result back, the addresses the driver discovered on
+// its own like the galera nodes are the service addresses already
+func (h *HealthMapped) serviceAddrs(typ, addr string) []string {
+	if services, ok := h.services[typ][addr]; ok {
+		return services
+	}
+	return []string{addr}
+}
+
+func (h *HealthMapped) BuildActiveBackends() (map[string]int, error) {
+	type checkResult struct {
+		typ      string
+		backends map[string]int
+		errors   map[string]error
+		err      error
+	}
+
+	results := make(chan checkResult, len(h.drivers))
+	for typ, driver := range h.drivers {
+		go func(typ string, driver healthDriver) {
+			backends, err := driver.BuildActiveBackends()
+			results <- checkResult{typ, backends, driver.ProbeErrors(), err}
+		}(typ, driver)
+	}
+
+	backends := make(map[string]int, MaxBackends)
+	h.errors = make(map[string]error, MaxBackends)
+
+	var err error
+	for i := 0; i < len(h.drivers); i++ {
+		r := <-results
+		if r.err != nil {
+			err = r.err
+			continue
+		}
+
+		for addr, flag := range r.backends {
+			for _, b := range h.serviceAddrs(r.typ, addr) {
+				backends[b] = flag
+			}
+		}
+		for addr, e := range r.errors {
+			for _, b := range h.serviceAddrs(r.typ, addr) {
+				h.errors[b] = e
+			}
+		}
+	}
+
+	// a failed driver only fails the check if nothing else is up
+	if len(backends) == 0 && err != nil {
+		return backends, err
+	}
+	return backends, nil
+}
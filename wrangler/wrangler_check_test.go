@@ -0,0 +1,120 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"github.com/zhgwenming/gbalancer/config"
+	"reflect"
+	"testing"
+)
+
+// fakeCheck reports the flags of its directors, the others are down
+type fakeCheck struct {
+	flags     map[string]int
+	directors []string
+	errors    map[string]error
+}
+
+func (f *fakeCheck) AddDirector(addr string) error {
+	f.directors = append(f.directors, addr)
+	return nil
+}
+
+func (f *fakeCheck) BuildActiveBackends() (map[string]int, error) {
+	backends := make(map[string]int)
+	f.errors = make(map[string]error)
+	for _, d := range f.directors {
+		if flag, ok := f.flags[d]; ok {
+			backends[d] = flag
+		} else {
+			f.errors[d] = errDown
+		}
+	}
+	return backends, nil
+}
+
+func (f *fakeCheck) ProbeErrors() map[string]error {
+	return f.errors
+}
+
+func (f *fakeCheck) Probe(addr string) (int, error) {
+	if flag, ok := f.flags[addr]; ok {
+		return flag, nil
+	}
+	return FlagDown, errDown
+}
+
+func TestHealthMapped(t *testing.T) {
+	c := &config.Configuration{
+		Service: "galera",
+		Check: map[string]config.CheckConfig{
+			"10.0.0.1:3306": {Addr: "10.0.0.1:9200", Type: "http"},
+			// two services behind the same check
+			"10.0.0.2:3306": {Port: "9200", Type: "http"},
+			"10.0.0.2:3307": {Port: "9200", Type: "http"},
+			"10.0.0.3:3306": {Port: "9200", Type: "http"},
+		},
+	}
+
+	checks := map[string]*fakeCheck{
+		"http":   {flags: map[string]int{"10.0.0.1:9200": FlagUp, "10.0.0.2:9200": FlagUp | 2<<WeightShift}},
+		"galera": {flags: map[string]int{"10.0.0.4:3306": FlagPrimary}},
+	}
+
+	h := NewHealthMapped()
+	for typ, check := range checks {
+		h.AddCheck(typ, check)
+	}
+	for _, b := range []string{"10.0.0.1:3306", "10.0.0.2:3306", "10.0.0.2:3307", "10.0.0.3:3306", "10.0.0.4:3306", "10.0.0.5:3306"} {
+		addr, typ, err := c.CheckTarget(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.AddBackend(b, addr, typ); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the shared check address is checked once
+	if directors := checks["http"].directors; len(directors) != 3 {
+		t.Errorf("unexpected http directors %v", directors)
+	}
+
+	backends, err := h.BuildActiveBackends()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int{
+		"10.0.0.1:3306": FlagUp,
+		"10.0.0.2:3306": FlagUp | 2<<WeightShift,
+		"10.0.0.2:3307": FlagUp | 2<<WeightShift,
+		"10.0.0.4:3306": FlagPrimary,
+	}
+	if !reflect.DeepEqual(backends, expected) {
+		t.Errorf("got %v, expected %v", backends, expected)
+	}
+
+	errors := h.ProbeErrors()
+	if len(errors) != 2 || errors["10.0.0.3:3306"] == nil || errors["10.0.0.5:3306"] == nil {
+		t.Errorf("unexpected probe errors %v", errors)
+	}
+
+	tests := []struct {
+		backend string
+		flag    int
+		fail    bool
+	}{
+		{"10.0.0.2:3307", FlagUp | 2<<WeightShift, false},
+		{"10.0.0.4:3306", FlagPrimary, false},
+		{"10.0.0.3:3306", FlagDown, true},
+		{"10.0.0.9:3306", FlagDown, true},
+	}
+	for _, tt := range tests {
+		flag, err := h.Probe(tt.backend)
+		if flag != tt.flag || (err != nil) != tt.fail {
+			t.Errorf("%s: got %d %v, expected %d", tt.backend, flag, err, tt.flag)
+		}
+	}
+}
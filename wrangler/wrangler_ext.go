@@ -6,18 +6,43 @@
 package wrangler
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/zhgwenming/gbalancer/config"
+	"net"
+	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 )
 
 type HealthExt struct {
 	Director   []string
 	ExtCommand string
+	Timeout    time.Duration
+	env        []string
+	errors     map[string]error
 }
 
-func NewHealthExt(cmd string) *HealthExt {
+func NewHealthExt(service, cmd string, settings *config.ExtConfig) (*HealthExt, error) {
+	timeout := ExtTimeout * time.Second
+	if settings.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(settings.Timeout); err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid Ext.Timeout %s", settings.Timeout)
+		}
+	}
+
+	env := append(os.Environ(), "GBALANCER_SERVICE="+service)
+	for k, v := range settings.Env {
+		env = append(env, k+"="+v)
+	}
+
 	dir := make([]string, 0, MaxBackends)
-	return &HealthExt{dir, cmd}
+	errors := make(map[string]error, MaxBackends)
+	return &HealthExt{dir, cmd, timeout, env, errors}, nil
 }
 
 func (h *HealthExt) AddDirector(backend string) error {
@@ -25,8 +50,89 @@ func (h *HealthExt) AddDirector(backend string) error {
 	return fmt.Errorf("Error to add backend %s\n", backend)
 }
 
-func extProbe(cmd, addr string) error {
-	return exec.Command(cmd, addr).Run()
+// Probe runs the command with the address, the flag comes from the state
+// and weight it prints
+func (h *HealthExt) Probe(addr string) (int, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command(h.ExtCommand, addr)
+	cmd.Stdout = &stdout
+	cmd.Env = append(cmd.Env, h.env...)
+	cmd.Env = append(cmd.Env, "GBALANCER_BACKEND="+addr)
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		cmd.Env = append(cmd.Env, "GBALANCER_HOST="+host, "GBALANCER_PORT="+port)
+	}
+
+	// a process group of its own so its children get killed with it
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return FlagDown, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return FlagDown, fmt.Errorf("%s: %s", addr, err)
+		}
+	case <-time.After(h.Timeout):
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+		return FlagDown, fmt.Errorf("%s: timed out after %s", addr, h.Timeout)
+	}
+
+	flag, err := parseExtOutput(stdout.String())
+	if err != nil {
+		return FlagDown, fmt.Errorf("%s: %s", addr, err)
+	}
+	return flag, nil
+}
+
+// parseExtOutput reads the "state=up|down|drain weight=N" the command
+// prints, it's up with the default weight if it prints neither
+func parseExtOutput(output string) (int, error) {
+	flag, weight := FlagUp, -1
+	for _, field := range strings.Fields(output) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch kv[0] {
+		case "state":
+			switch kv[1] {
+			case "up":
+			case "drain":
+				flag |= FlagDrain
+			case "down":
+				return FlagDown, fmt.Errorf("reported down")
+			default:
+				return FlagDown, fmt.Errorf("unknown state %s", kv[1])
+			}
+		case "weight":
+			w, err := strconv.Atoi(kv[1])
+			if err != nil || w < 0 || w > MaxWeight {
+				return FlagDown, fmt.Errorf("invalid weight %s", kv[1])
+			}
+			weight = w
+		}
+	}
+
+	// no new connections with a weight of 0
+	if weight == 0 {
+		flag |= FlagDrain
+	} else if weight > 0 {
+		flag |= weight << WeightShift
+	}
+	return flag, nil
+}
+
+// the probe errors of the last check
+func (t *HealthExt) ProbeErrors() map[string]error {
+	return t.errors
 }
 
 // check the backend status
@@ -39,28 +145,31 @@ func (t *HealthExt) BuildActiveBackends() (map[string]int, error) {
 
 	type backendStatus struct {
 		backend string
+		flag    int
 		err     error
 	}
 
 	results := make(chan backendStatus, MaxBackends)
+	t.errors = make(map[string]error, MaxBackends)
 
-	probe := func(cmd, addr string) {
-		err := extProbe(cmd, addr)
-		results <- backendStatus{addr, err}
+	probe := func(addr string) {
+		flag, err := t.Probe(addr)
+		results <- backendStatus{addr, flag, err}
 	}
 
 	numWorkers := 0
 	for _, addr := range t.Director {
-		go probe(t.ExtCommand, addr)
+		go probe(addr)
 		numWorkers++
 	}
 	for i := 0; i < numWorkers; i++ {
 		r := <-results
 		if r.err == nil {
-			backends[r.backend] = FlagUp
+			backends[r.backend] = r.flag
 			//log.Printf("host: %s\n", r.backend)
 		} else {
-			log.Printf("ext error: %s", r.err)
+			t.errors[r.backend] = r.err
+			log.Warnf("ext error: %s", r.err)
 		}
 	}
 	//log.Printf("Active server: %v\n", backends)
@@ -0,0 +1,108 @@
+// +build linux darwin
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"github.com/zhgwenming/gbalancer/config"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExtOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		flag   int
+		fail   bool
+	}{
+		{"", FlagUp, false},
+		{"\n", FlagUp, false},
+		{"state=up", FlagUp, false},
+		{"state=up weight=50\n", FlagUp | 50<<WeightShift, false},
+		{"state=drain", FlagUp | FlagDrain, false},
+		{"state=drain weight=20", FlagUp | FlagDrain | 20<<WeightShift, false},
+		{"weight=0", FlagUp | FlagDrain, false},
+		{"state=up weight=0", FlagUp | FlagDrain, false},
+		{"checked ok state=up", FlagUp, false},
+		{"state=down", FlagDown, true},
+		{"state=maint", FlagDown, true},
+		{"weight=-1", FlagDown, true},
+		{"weight=10001", FlagDown, true},
+		{"weight=high", FlagDown, true},
+	}
+
+	for _, tt := range tests {
+		flag, err := parseExtOutput(tt.output)
+		if (err != nil) != tt.fail {
+			t.Errorf("%q: unexpected error %v", tt.output, err)
+		}
+		if flag != tt.flag {
+			t.Errorf("%q: expected flag %#x, got %#x", tt.output, tt.flag, flag)
+		}
+	}
+}
+
+// alive tells if the process is still there, zombies are gone already
+func alive(pid string) bool {
+	out, err := exec.Command("ps", "-o", "stat=", "-p", pid).Output()
+	return err == nil && !strings.HasPrefix(strings.TrimSpace(string(out)), "Z")
+}
+
+func TestExtBadTimeout(t *testing.T) {
+	for _, timeout := range []string{"0", "0s", "-1s", "soon"} {
+		if _, err := NewHealthExt("mysql", "/bin/true", &config.ExtConfig{Timeout: timeout}); err == nil {
+			t.Errorf("%s: expected an error", timeout)
+		}
+	}
+}
+
+func TestExtTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the child keeps the output open after the script got killed
+	pids := filepath.Join(dir, "pids")
+	script := filepath.Join(dir, "check.sh")
+	content := "#!/bin/sh\nsleep 60 &\necho $$ $! > " + pids + "\nsleep 60\n"
+	if err := ioutil.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := NewHealthExt("mysql", script, &config.ExtConfig{Timeout: "500ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := h.Probe("127.0.0.1:3306"); err == nil {
+		t.Fatal("expected the hung script to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("probe took %s", elapsed)
+	}
+
+	data, err := ioutil.ReadFile(pids)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pid := range strings.Fields(string(data)) {
+		for i := 0; alive(pid); i++ {
+			if i == 50 {
+				t.Errorf("process %s still running", pid)
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
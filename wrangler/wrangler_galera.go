@@ -8,6 +8,10 @@ import (
 	"database/sql"
 	"fmt"
 	_ "github.com/zhgwenming/gbalancer/Godeps/_workspace/src/github.com/go-sql-driver/mysql"
+	"github.com/zhgwenming/gbalancer/config"
+	"net"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -32,12 +36,25 @@ import (
 type Galera struct {
 	User     string
 	Pass     string
-	Director []string // directory server, order sensitive, will use the first one by default
+	Director []string // directory server, the first ones win the ties between components
+	// addresses of the nodes by wsrep_node_name, and the rewrites of the
+	// reported addresses
+	Nodes     map[string]string
+	Addresses map[string]string
+	errors    map[string]error
+	probe     func(user, pass, host string) (map[string]string, error)
 }
 
-func NewGalera(user, pass string) *Galera {
+func NewGalera(user, pass string, settings *config.GaleraConfig) (*Galera, error) {
+	for name, addr := range settings.Nodes {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("Galera.Nodes %s: %s", name, err)
+		}
+	}
+
 	dir := make([]string, 0, MaxBackends)
-	return &Galera{user, pass, dir}
+	errors := make(map[string]error, MaxBackends)
+	return &Galera{user, pass, dir, settings.Nodes, settings.Addresses, errors, galeraProbe}, nil
 }
 
 func (c *Galera) AddDirector(backend string) error {
@@ -52,6 +69,11 @@ func galeraProbe(user, pass, host string) (map[string]string, error) {
 	var wsrep_status = map[string]string{
 		WsrepConnected: "",
 		WsrepAddresses: "",
+		WsrepStatus:    "",
+		WsrepUUID:      "",
+		WsrepSize:      "",
+		WsrepNodeName:  "",
+		WsrepNodeAddr:  "",
 	}
 
 	// user:password@tcp(db.example.com:3306)/dbname
@@ -65,25 +87,39 @@ func galeraProbe(user, pass, host string) (map[string]string, error) {
 	}
 	defer db.Close()
 
-	rows, err := db.Query("show status like 'wsrep_%'")
-	if err != nil {
-		//log.Printf("%s\n", err)
+	scan := func(query string) error {
+		rows, err := db.Query(query)
+		if err != nil {
+			//log.Printf("%s\n", err)
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var key string
+			var value string
+			err = rows.Scan(&key, &value)
+			if _, ok := wsrep_status[key]; ok {
+				wsrep_status[key] = value
+				//if !all {
+				//	log.Printf("%s %s\n", key, value)
+				//}
+			}
+			if all {
+				log.Debugf("%s %s\n", key, value)
+			}
+		}
+		return nil
+	}
+
+	if err := scan("show status like 'wsrep_%'"); err != nil {
 		return wsrep_status, err
 	}
 
-	for rows.Next() {
-		var key string
-		var value string
-		err = rows.Scan(&key, &value)
-		if _, ok := wsrep_status[key]; ok {
-			wsrep_status[key] = value
-			//if !all {
-			//	log.Printf("%s %s\n", key, value)
-			//}
-		}
-		if all {
-			log.Printf("%s %s\n", key, value)
-		}
+	// the name and the own address of the node only help to tell the nodes
+	// apart, the check goes on without them
+	if err := scan("show variables like 'wsrep_node_%'"); err != nil {
+		log.Debugf("%s: %s\n", host, err)
 	}
 
 	err = fmt.Errorf("Galera Not Connected")
@@ -95,10 +131,158 @@ func galeraProbe(user, pass, host string) (map[string]string, error) {
 
 type backendStatus struct {
 	backend string
+	status  map[string]string
 	err     error
 }
 
-// check the backend status
+// the probe errors of the last check
+func (c *Galera) ProbeErrors() map[string]error {
+	return c.errors
+}
+
+// translate returns the address to dial for an address of
+// wsrep_incoming_addresses, empty if it can't be dialed
+func (c *Galera) translate(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+
+	to, ok := c.Addresses[addr]
+	if !ok {
+		to, ok = c.Addresses[host]
+	}
+	if ok {
+		if _, _, err := net.SplitHostPort(to); err == nil {
+			return to
+		}
+		host = to
+	}
+
+	// AUTO or a wildcard the node listens on
+	if ip := net.ParseIP(host); host == "" || host == "AUTO" || ip != nil && ip.IsUnspecified() {
+		return ""
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// probeAll checks the directors, the Nodes and every node they know of,
+// until no new node turns up. The nodes come in the order they were found.
+func (c *Galera) probeAll() ([]string, map[string]backendStatus) {
+	nodes := make(map[string]backendStatus, MaxBackends)
+	order := make([]string, 0, MaxBackends)
+	results := make(chan backendStatus, MaxBackends)
+	// the reported addresses of the nodes already reached
+	reached := make(map[string]bool, MaxBackends)
+
+	pending := append([]string(nil), c.Director...)
+	named := make([]string, 0, len(c.Nodes))
+	for _, addr := range c.Nodes {
+		named = append(named, addr)
+	}
+	sort.Strings(named)
+	pending = append(pending, named...)
+	for len(pending) > 0 && uint(len(order)) < MaxBackends {
+		numWorkers := 0
+		for _, addr := range pending {
+			if _, ok := nodes[addr]; ok {
+				continue
+			}
+			if uint(len(order)) >= MaxBackends {
+				log.Warnf("galera: more than %d nodes, stopped the discovery at %s\n", MaxBackends, addr)
+				break
+			}
+			nodes[addr] = backendStatus{addr, nil, nil}
+			order = append(order, addr)
+
+			go func(addr string) {
+				status, err := c.probe(c.User, c.Pass, addr)
+				results <- backendStatus{addr, status, err}
+			}(addr)
+			numWorkers++
+		}
+
+		incoming := make([]string, 0, MaxBackends)
+		for i := 0; i < numWorkers; i++ {
+			r := <-results
+			nodes[r.backend] = r
+			if r.err != nil {
+				continue
+			}
+			if own := r.status[WsrepNodeAddr]; own != "" && own != "AUTO" {
+				reached[own] = true
+			}
+			if r.status[WsrepAddresses] != "" {
+				incoming = append(incoming, strings.Split(r.status[WsrepAddresses], ",")...)
+			}
+		}
+
+		found := make([]string, 0, MaxBackends)
+		// the reported addresses handled and the translated ones queued
+		seen := make(map[string]bool, MaxBackends)
+		queued := make(map[string]bool, MaxBackends)
+		for _, addr := range incoming {
+			if addr == "" || reached[addr] || seen[addr] {
+				continue
+			}
+			seen[addr] = true
+
+			to := c.translate(addr)
+			if to == "" {
+				log.Debugf("galera: skipping the node address %s\n", addr)
+				continue
+			}
+			if _, ok := nodes[to]; !ok && !queued[to] {
+				queued[to] = true
+				found = append(found, to)
+			}
+		}
+		sort.Strings(found)
+		pending = found
+	}
+	return order, nodes
+}
+
+// primaryComponent picks the cluster state uuid of the Primary component.
+// The nodes of a healthy cluster all agree on it, otherwise the component
+// reporting the most nodes wins.
+func primaryComponent(order []string, nodes map[string]backendStatus) string {
+	sizes := make(map[string]int)
+	uuids := make([]string, 0)
+	for _, addr := range order {
+		n := nodes[addr]
+		if n.err != nil || n.status[WsrepStatus] != "Primary" {
+			continue
+		}
+
+		uuid := n.status[WsrepUUID]
+		size, _ := strconv.Atoi(n.status[WsrepSize])
+		if _, ok := sizes[uuid]; !ok {
+			uuids = append(uuids, uuid)
+		}
+		if size > sizes[uuid] {
+			sizes[uuid] = size
+		}
+	}
+
+	if len(uuids) == 0 {
+		return ""
+	}
+
+	primary := uuids[0]
+	for _, uuid := range uuids[1:] {
+		if sizes[uuid] > sizes[primary] {
+			primary = uuid
+		}
+	}
+	if len(uuids) > 1 {
+		log.Warnf("galera nodes disagree on the cluster state uuid %v, sizes %v, using %s\n",
+			uuids, sizes, primary)
+	}
+	return primary
+}
+
+// check the backend status, only the nodes of the Primary component are up
 func (c *Galera) BuildActiveBackends() (map[string]int, error) {
 	backends := make(map[string]int, MaxBackends)
 
@@ -106,54 +290,53 @@ func (c *Galera) BuildActiveBackends() (map[string]int, error) {
 		return backends, fmt.Errorf("Empty directory server list\n")
 	}
 
-	results := make(chan backendStatus, MaxBackends)
+	c.errors = make(map[string]error, MaxBackends)
+	order, nodes := c.probeAll()
+	primary := primaryComponent(order, nodes)
+
+	failed := make(map[string]error, len(order))
+	for _, addr := range order {
+		n := nodes[addr]
+		err := n.err
+		switch {
+		case err != nil:
+		case n.status[WsrepAddresses] == "":
+			err = fmt.Errorf("%s: %s doesn't exist in status, not a galera cluster?", addr, WsrepAddresses)
+		case n.status[WsrepStatus] != "Primary":
+			err = fmt.Errorf("%s: cluster status %s", addr, n.status[WsrepStatus])
+		case n.status[WsrepUUID] != primary:
+			err = fmt.Errorf("%s: cluster %s isn't the primary component %s", addr, n.status[WsrepUUID], primary)
+		}
 
-	probe := func(user, pass, addr string) {
-		_, err := galeraProbe(c.User, c.Pass, addr)
-		results <- backendStatus{addr, err}
-		//if err != nil {
-		//	log.Printf("probe: %s\n", err)
-		//}
+		failed[addr] = err
 	}
 
-	for dirIndex, dirAddr := range c.Director {
-		status, err := galeraProbe(c.User, c.Pass, dirAddr)
-		if err != nil {
-			log.Println(err)
+	// a node reached on more than one address is up on the one of Nodes,
+	// or on the first one
+	seen := make(map[string]bool, len(order))
+	for _, addr := range order {
+		if err := failed[addr]; err != nil {
+			c.errors[addr] = err
+			log.Warnf("node not ready: %s", err)
 			continue
 		}
 
-		backends[dirAddr] = FlagUp
-		if dirIndex != 0 {
-			c.Director[0], c.Director[dirIndex] = c.Director[dirIndex], c.Director[0]
-			log.Printf("Make %s as the first director\n", dirAddr)
+		name := nodes[addr].status[WsrepNodeName]
+		if name == "" {
+			backends[addr] = FlagUp
+			continue
 		}
 
-		if val, ok := status[WsrepAddresses]; ok && val != "" {
-			addrs := strings.Split(val, ",")
-			numWorkers := 0
-			for _, addr := range addrs {
-				// director server itself already probed, skip
-				if addr == dirAddr {
-					continue
-				}
-
-				go probe(c.User, c.Pass, addr)
-				numWorkers++
-			}
-			for i := 0; i < numWorkers; i++ {
-				r := <-results
-				if r.err == nil {
-					backends[r.backend] = FlagUp
-					//log.Printf("host: %s\n", r.backend)
-				} else {
-					log.Printf("node not ready: %s", r.err)
-				}
-			}
-			break
-		} else {
-			log.Printf("host %s: %s key doesn't exist in status, not a galera cluster?\n", dirAddr, WsrepAddresses)
-			continue
+		want, ok := c.Nodes[name]
+		_, reachable := nodes[want]
+		switch {
+		case ok && want != addr && reachable && failed[want] == nil:
+			log.Debugf("galera: %s is node %s, reached on %s\n", addr, name, want)
+		case seen[name]:
+			log.Debugf("galera: %s is node %s, reached already\n", addr, name)
+		default:
+			seen[name] = true
+			backends[addr] = FlagUp
 		}
 	}
 	//log.Printf("Active server: %v\n", backends)
@@ -0,0 +1,286 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"fmt"
+	"github.com/zhgwenming/gbalancer/config"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// wsrep returns the status of a connected node
+func wsrep(name, addr, status, uuid string, size int, incoming ...string) map[string]string {
+	return map[string]string{
+		WsrepConnected: "ON",
+		WsrepAddresses: strings.Join(incoming, ","),
+		WsrepStatus:    status,
+		WsrepUUID:      uuid,
+		WsrepSize:      strconv.Itoa(size),
+		WsrepNodeName:  name,
+		WsrepNodeAddr:  addr,
+	}
+}
+
+// fakeGalera answers the probes from the status of the nodes, the others
+// can't be reached
+type fakeGalera struct {
+	sync.Mutex
+	nodes  map[string]map[string]string
+	probed []string
+}
+
+func (f *fakeGalera) probe(user, pass, host string) (map[string]string, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.probed = append(f.probed, host)
+	if status, ok := f.nodes[host]; ok {
+		return status, nil
+	}
+	return nil, fmt.Errorf("%s unreachable", host)
+}
+
+func newTestGalera(t *testing.T, settings *config.GaleraConfig, f *fakeGalera, directors ...string) *Galera {
+	g, err := NewGalera("monitor", "", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.probe = f.probe
+	for _, d := range directors {
+		g.AddDirector(d)
+	}
+	return g
+}
+
+func TestPrimaryComponent(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   []map[string]string
+		primary string
+	}{
+		{"agreed", []map[string]string{
+			wsrep("n1", "", "Primary", "a", 2),
+			wsrep("n2", "", "Primary", "a", 2),
+		}, "a"},
+		{"non-Primary node", []map[string]string{
+			wsrep("n1", "", "non-Primary", "b", 5),
+			wsrep("n2", "", "Primary", "a", 2),
+		}, "a"},
+		{"no Primary node", []map[string]string{
+			wsrep("n1", "", "non-Primary", "a", 1),
+			wsrep("n2", "", "Disconnected", "a", 1),
+		}, ""},
+		{"largest component wins", []map[string]string{
+			wsrep("n1", "", "Primary", "a", 1),
+			wsrep("n2", "", "Primary", "b", 2),
+			wsrep("n3", "", "Primary", "b", 2),
+		}, "b"},
+		{"tie goes to the first", []map[string]string{
+			wsrep("n1", "", "Primary", "a", 2),
+			wsrep("n2", "", "Primary", "b", 2),
+		}, "a"},
+		{"stale size", []map[string]string{
+			wsrep("n1", "", "Primary", "a", 3),
+			wsrep("n2", "", "Primary", "b", 2),
+			wsrep("n3", "", "Primary", "b", 2),
+		}, "a"},
+	}
+
+	for _, tt := range tests {
+		order := make([]string, 0, len(tt.nodes))
+		nodes := make(map[string]backendStatus, len(tt.nodes))
+		for i, status := range tt.nodes {
+			addr := "10.0.0." + strconv.Itoa(i+1) + ":3306"
+			order = append(order, addr)
+			nodes[addr] = backendStatus{addr, status, nil}
+		}
+
+		// the failed probes never count
+		order = append(order, "10.0.0.9:3306")
+		nodes["10.0.0.9:3306"] = backendStatus{"10.0.0.9:3306", wsrep("n9", "", "Primary", "c", 9),
+			fmt.Errorf("timeout")}
+
+		if primary := primaryComponent(order, nodes); primary != tt.primary {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.primary, primary)
+		}
+	}
+}
+
+func TestGaleraBackends(t *testing.T) {
+	all := []string{"10.0.0.1:3306", "10.0.0.2:3306", "10.0.0.3:3306"}
+
+	tests := []struct {
+		name      string
+		nodes     map[string]map[string]string
+		settings  *config.GaleraConfig
+		directors []string
+		up        []string
+	}{
+		{"healthy", map[string]map[string]string{
+			"10.0.0.1:3306": wsrep("n1", "10.0.0.1:3306", "Primary", "a", 3, all...),
+			"10.0.0.2:3306": wsrep("n2", "10.0.0.2:3306", "Primary", "a", 3, all...),
+			"10.0.0.3:3306": wsrep("n3", "10.0.0.3:3306", "Primary", "a", 3, all...),
+		}, &config.GaleraConfig{}, []string{"10.0.0.1:3306"}, all},
+		{"non-Primary node", map[string]map[string]string{
+			"10.0.0.1:3306": wsrep("n1", "10.0.0.1:3306", "Primary", "a", 2, all[:2]...),
+			"10.0.0.2:3306": wsrep("n2", "10.0.0.2:3306", "Primary", "a", 2, all[:2]...),
+			"10.0.0.3:3306": wsrep("n3", "10.0.0.3:3306", "non-Primary", "a", 1, all[2]),
+		}, &config.GaleraConfig{}, []string{"10.0.0.3:3306", "10.0.0.1:3306"}, all[:2]},
+		{"disagreeing uuids", map[string]map[string]string{
+			"10.0.0.1:3306": wsrep("n1", "10.0.0.1:3306", "Primary", "a", 2, all...),
+			"10.0.0.2:3306": wsrep("n2", "10.0.0.2:3306", "Primary", "a", 2, all...),
+			"10.0.0.3:3306": wsrep("n3", "10.0.0.3:3306", "Primary", "b", 1, all...),
+		}, &config.GaleraConfig{}, []string{"10.0.0.1:3306"}, all[:2]},
+		{"largest component wins", map[string]map[string]string{
+			"10.0.0.1:3306": wsrep("n1", "10.0.0.1:3306", "Primary", "a", 1, all[0]),
+			"10.0.0.2:3306": wsrep("n2", "10.0.0.2:3306", "Primary", "b", 2, all[1:]...),
+			"10.0.0.3:3306": wsrep("n3", "10.0.0.3:3306", "Primary", "b", 2, all[1:]...),
+		}, &config.GaleraConfig{}, []string{"10.0.0.1:3306", "10.0.0.2:3306"}, all[1:]},
+		{"one node reached on two addresses", map[string]map[string]string{
+			"db.vip:3306":   wsrep("n1", "AUTO", "Primary", "a", 2, all[:2]...),
+			"10.0.0.1:3306": wsrep("n1", "AUTO", "Primary", "a", 2, all[:2]...),
+			"10.0.0.2:3306": wsrep("n2", "AUTO", "Primary", "a", 2, all[:2]...),
+		}, &config.GaleraConfig{}, []string{"db.vip:3306"}, []string{"db.vip:3306", "10.0.0.2:3306"}},
+		{"one node reached on two addresses, Nodes first", map[string]map[string]string{
+			"db.vip:3306":   wsrep("n1", "AUTO", "Primary", "a", 2, all[:2]...),
+			"10.0.0.1:3306": wsrep("n1", "AUTO", "Primary", "a", 2, all[:2]...),
+			"10.0.0.2:3306": wsrep("n2", "AUTO", "Primary", "a", 2, all[:2]...),
+		}, &config.GaleraConfig{Nodes: map[string]string{"n1": "10.0.0.1:3306"}},
+			[]string{"db.vip:3306"}, all[:2]},
+	}
+
+	for _, tt := range tests {
+		f := &fakeGalera{nodes: tt.nodes}
+		g := newTestGalera(t, tt.settings, f, tt.directors...)
+
+		backends, err := g.BuildActiveBackends()
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+
+		expected := make(map[string]int, len(tt.up))
+		for _, addr := range tt.up {
+			expected[addr] = FlagUp
+		}
+		if !reflect.DeepEqual(backends, expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, expected, backends)
+		}
+
+		// every address is probed once
+		seen := make(map[string]bool, len(f.probed))
+		for _, addr := range f.probed {
+			if seen[addr] {
+				t.Errorf("%s: %s probed twice", tt.name, addr)
+			}
+			seen[addr] = true
+		}
+	}
+}
+
+func TestGaleraMaxBackends(t *testing.T) {
+	// every node knows of two more nodes
+	f := &fakeGalera{nodes: make(map[string]map[string]string)}
+	for i := 1; i <= 4*int(MaxBackends); i++ {
+		addr := fmt.Sprintf("10.0.%d.%d:3306", i/256, i%256)
+		next := []string{
+			fmt.Sprintf("10.0.%d.%d:3306", 2*i/256, 2*i%256),
+			fmt.Sprintf("10.0.%d.%d:3306", (2*i+1)/256, (2*i+1)%256),
+		}
+		f.nodes[addr] = wsrep("n"+strconv.Itoa(i), addr, "Primary", "a", 4*int(MaxBackends), next...)
+	}
+
+	g := newTestGalera(t, &config.GaleraConfig{}, f, "10.0.0.1:3306")
+	order, nodes := g.probeAll()
+	if uint(len(order)) != MaxBackends || uint(len(nodes)) != MaxBackends {
+		t.Errorf("expected %d nodes, got %d in order and %d probed", MaxBackends, len(order), len(nodes))
+	}
+	if uint(len(f.probed)) != MaxBackends {
+		t.Errorf("expected %d probes, got %d", MaxBackends, len(f.probed))
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	g, err := NewGalera("monitor", "", &config.GaleraConfig{Addresses: map[string]string{
+		"192.168.0.1:3306": "10.0.0.1:3306",
+		"192.168.0.1":      "10.0.0.9:3306",
+		"192.168.0.2":      "10.0.0.2",
+		"192.168.0.3":      "db3.example.com:4306",
+		"0.0.0.0:3306":     "10.0.0.4:3306",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		addr string
+		to   string
+	}{
+		// the host:port key goes before the bare host one
+		{"192.168.0.1:3306", "10.0.0.1:3306"},
+		{"192.168.0.1:4306", "10.0.0.9:3306"},
+		// a rewrite to a host keeps the port
+		{"192.168.0.2:3306", "10.0.0.2:3306"},
+		{"192.168.0.2:4306", "10.0.0.2:4306"},
+		{"192.168.0.3:3306", "db3.example.com:4306"},
+		// not rewritten
+		{"10.0.0.5:3306", "10.0.0.5:3306"},
+		{"[fe80::1]:3306", "[fe80::1]:3306"},
+		// can't be dialed without a rewrite
+		{"AUTO", ""},
+		{"AUTO:3306", ""},
+		{":3306", ""},
+		{"0.0.0.0:4306", ""},
+		{"[::]:3306", ""},
+		{"0.0.0.0:3306", "10.0.0.4:3306"},
+	}
+
+	for _, tt := range tests {
+		if to := g.translate(tt.addr); to != tt.to {
+			t.Errorf("%s: expected %q, got %q", tt.addr, tt.to, to)
+		}
+	}
+}
+
+func TestGaleraNodes(t *testing.T) {
+	internal := []string{"192.168.0.1:3306", "192.168.0.2:3306"}
+	f := &fakeGalera{nodes: map[string]map[string]string{
+		"db.vip:3306":   wsrep("n2", "192.168.0.2:3306", "Primary", "a", 2, internal...),
+		"10.0.0.1:3306": wsrep("n1", "192.168.0.1:3306", "Primary", "a", 2, internal...),
+		"10.0.0.2:3306": wsrep("n2", "192.168.0.2:3306", "Primary", "a", 2, internal...),
+	}}
+	settings := &config.GaleraConfig{
+		Nodes:     map[string]string{"n2": "10.0.0.2:3306"},
+		Addresses: map[string]string{"192.168.0.1": "10.0.0.1"},
+	}
+
+	// n2 is reached on the director first, then on its address of Nodes
+	g := newTestGalera(t, settings, f, "db.vip:3306")
+	backends, err := g.BuildActiveBackends()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{"10.0.0.1:3306": FlagUp, "10.0.0.2:3306": FlagUp}
+	if !reflect.DeepEqual(backends, expected) {
+		t.Errorf("expected %v, got %v", expected, backends)
+	}
+
+	// Nodes down, the director is used instead
+	delete(f.nodes, "10.0.0.2:3306")
+	backends, err = g.BuildActiveBackends()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = map[string]int{"10.0.0.1:3306": FlagUp, "db.vip:3306": FlagUp}
+	if !reflect.DeepEqual(backends, expected) {
+		t.Errorf("expected %v, got %v", expected, backends)
+	}
+}
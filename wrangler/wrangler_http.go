@@ -6,17 +6,139 @@
 package wrangler
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"github.com/zhgwenming/gbalancer/config"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
+type statusRange struct {
+	low  int
+	high int
+}
+
 type HealthHTTP struct {
 	Director []string
+	errors   map[string]error
+
+	client     *http.Client
+	scheme     string
+	method     string
+	path       string
+	host       string
+	status     []statusRange
+	body       string
+	bodyRegexp *regexp.Regexp
 }
 
-func NewHealthHTTP() *HealthHTTP {
+func NewHealthHTTP(settings *config.HTTPCheckConfig) (*HealthHTTP, error) {
 	dir := make([]string, 0, MaxBackends)
-	return &HealthHTTP{dir}
+	errors := make(map[string]error, MaxBackends)
+	h := &HealthHTTP{Director: dir, errors: errors, scheme: "http", method: "GET", path: "/", host: settings.Host, body: settings.Body}
+
+	if settings.Method != "" {
+		h.method = strings.ToUpper(settings.Method)
+	}
+	if settings.Path != "" {
+		h.path = settings.Path
+		if !strings.HasPrefix(h.path, "/") {
+			h.path = "/" + h.path
+		}
+	}
+
+	status := settings.Status
+	if status == "" {
+		status = HTTPStatus
+	}
+	var err error
+	if h.status, err = parseStatus(status); err != nil {
+		return nil, err
+	}
+
+	if settings.BodyRegexp != "" {
+		if h.bodyRegexp, err = regexp.Compile(settings.BodyRegexp); err != nil {
+			return nil, fmt.Errorf("HTTPCheck.BodyRegexp: %s", err)
+		}
+	}
+
+	if h.method == "HEAD" && (h.body != "" || h.bodyRegexp != nil) {
+		return nil, fmt.Errorf("HTTPCheck: HEAD responses have no body to match")
+	}
+
+	timeout := HTTPTimeout * time.Second
+	if settings.Timeout != "" {
+		if timeout, err = time.ParseDuration(settings.Timeout); err != nil {
+			return nil, fmt.Errorf("HTTPCheck.Timeout: %s", err)
+		}
+	}
+
+	// every probe makes a new connection
+	transport := &http.Transport{
+		Dial:              (&net.Dialer{Timeout: timeout}).Dial,
+		DisableKeepAlives: true,
+	}
+
+	if settings.HTTPS {
+		h.scheme = "https"
+		tlsConfig := &tls.Config{InsecureSkipVerify: settings.InsecureSkipVerify}
+		if settings.Host != "" {
+			host, _, err := net.SplitHostPort(settings.Host)
+			if err != nil {
+				host = settings.Host
+			}
+			tlsConfig.ServerName = host
+		}
+
+		if settings.CA != "" {
+			pem, err := ioutil.ReadFile(settings.CA)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificate found in %s", settings.CA)
+			}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	h.client = &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		// the redirects are judged by the status
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return h, nil
+}
+
+// parseStatus parses the status codes like "200-299,301"
+func parseStatus(s string) ([]statusRange, error) {
+	var ranges []statusRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		bounds := strings.SplitN(part, "-", 2)
+
+		low, err := strconv.Atoi(bounds[0])
+		high := low
+		if err == nil && len(bounds) == 2 {
+			high, err = strconv.Atoi(bounds[1])
+		}
+		if err != nil || low < 100 || high > 599 || low > high {
+			return nil, fmt.Errorf("invalid HTTPCheck.Status %q", part)
+		}
+		ranges = append(ranges, statusRange{low, high})
+	}
+	return ranges, nil
 }
 
 func (h *HealthHTTP) AddDirector(backend string) error {
@@ -24,15 +146,62 @@ func (h *HealthHTTP) AddDirector(backend string) error {
 	return fmt.Errorf("Error to add backend %s\n", backend)
 }
 
-func httpProbe(addr string) error {
-	resp, err := http.Get("http://" + addr + "/")
+func (h *HealthHTTP) probe(addr string) error {
+	req, err := http.NewRequest(h.method, h.scheme+"://"+addr+h.path, nil)
+	if err != nil {
+		return err
+	}
+	if h.host != "" {
+		req.Host = h.host
+	}
+
+	resp, err := h.client.Do(req)
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
+
+	healthy := false
+	for _, r := range h.status {
+		if resp.StatusCode >= r.low && resp.StatusCode <= r.high {
+			healthy = true
+			break
+		}
+	}
+	if !healthy {
+		return fmt.Errorf("%s %s: unexpected status %s", h.method, req.URL, resp.Status)
+	}
+
+	if h.body == "" && h.bodyRegexp == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxHTTPBody))
+	if err != nil {
+		return err
+	}
+	if h.body != "" && !strings.Contains(string(body), h.body) {
+		return fmt.Errorf("%s %s: body doesn't contain %q", h.method, req.URL, h.body)
+	}
+	if h.bodyRegexp != nil && !h.bodyRegexp.Match(body) {
+		return fmt.Errorf("%s %s: body doesn't match %q", h.method, req.URL, h.bodyRegexp)
+	}
 	return nil
 }
 
+// Probe checks a single backend
+func (h *HealthHTTP) Probe(addr string) (int, error) {
+	if err := h.probe(addr); err != nil {
+		return FlagDown, err
+	}
+	return FlagUp, nil
+}
+
+// the probe errors of the last check
+func (t *HealthHTTP) ProbeErrors() map[string]error {
+	return t.errors
+}
+
 // check the backend status
 func (t *HealthHTTP) BuildActiveBackends() (map[string]int, error) {
 	backends := make(map[string]int, MaxBackends)
@@ -47,9 +216,10 @@ func (t *HealthHTTP) BuildActiveBackends() (map[string]int, error) {
 	}
 
 	results := make(chan backendStatus, MaxBackends)
+	t.errors = make(map[string]error, MaxBackends)
 
 	probe := func(addr string) {
-		err := httpProbe(addr)
+		err := t.probe(addr)
 		results <- backendStatus{addr, err}
 	}
 
@@ -64,7 +234,8 @@ func (t *HealthHTTP) BuildActiveBackends() (map[string]int, error) {
 			backends[r.backend] = FlagUp
 			//log.Printf("host: %s\n", r.backend)
 		} else {
-			log.Printf("http error: %s", r.err)
+			t.errors[r.backend] = r.err
+			log.Warnf("http error: %s", r.err)
 		}
 	}
 	//log.Printf("Active server: %v\n", backends)
@@ -0,0 +1,119 @@
+// +build linux darwin
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"fmt"
+	"github.com/zhgwenming/gbalancer/config"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		ranges []statusRange
+		fail   bool
+	}{
+		{"200", []statusRange{{200, 200}}, false},
+		{"200-399", []statusRange{{200, 399}}, false},
+		{"200-299, 301,404", []statusRange{{200, 299}, {301, 301}, {404, 404}}, false},
+		{"100-599", []statusRange{{100, 599}}, false},
+		{"", nil, true},
+		{"99", nil, true},
+		{"600", nil, true},
+		{"300-200", nil, true},
+		{"200-", nil, true},
+		{"2xx", nil, true},
+		{"200,,300", nil, true},
+	}
+
+	for _, tt := range tests {
+		ranges, err := parseStatus(tt.status)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", tt.status, ranges)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", tt.status, err)
+		} else if !reflect.DeepEqual(ranges, tt.ranges) {
+			t.Errorf("%q: expected %v, got %v", tt.status, tt.ranges, ranges)
+		}
+	}
+}
+
+func TestHTTPProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var code int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/"), "%d", &code)
+		if code == 0 {
+			code = 200
+		}
+		w.WriteHeader(code)
+		fmt.Fprintf(w, "status: ok, host: %s", r.Host)
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	tests := []struct {
+		name     string
+		settings config.HTTPCheckConfig
+		up       bool
+	}{
+		{"default status", config.HTTPCheckConfig{}, true},
+		{"redirect within the default", config.HTTPCheckConfig{Path: "/302"}, true},
+		{"error", config.HTTPCheckConfig{Path: "/500"}, false},
+		{"not found", config.HTTPCheckConfig{Path: "404"}, false},
+		{"listed status", config.HTTPCheckConfig{Path: "/404", Status: "200,404"}, true},
+		{"out of the range", config.HTTPCheckConfig{Path: "/204", Status: "200-203,205-299"}, false},
+		{"body", config.HTTPCheckConfig{Body: "status: ok"}, true},
+		{"body mismatch", config.HTTPCheckConfig{Body: "status: down"}, false},
+		{"body of a bad status", config.HTTPCheckConfig{Path: "/503", Body: "status: ok"}, false},
+		{"regexp", config.HTTPCheckConfig{BodyRegexp: `^status: (ok|degraded)`}, true},
+		{"regexp mismatch", config.HTTPCheckConfig{BodyRegexp: `^status: down`}, false},
+		{"host", config.HTTPCheckConfig{Host: "app.example.com", Body: "host: app.example.com"}, true},
+		{"body and regexp", config.HTTPCheckConfig{Body: "ok", BodyRegexp: `host: 127\.`}, true},
+		{"body and regexp mismatch", config.HTTPCheckConfig{Body: "ok", BodyRegexp: `host: 10\.`}, false},
+	}
+
+	for _, tt := range tests {
+		h, err := NewHealthHTTP(&tt.settings)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		flag, err := h.Probe(addr)
+		if tt.up && (err != nil || flag != FlagUp) {
+			t.Errorf("%s: expected up, got %d %v", tt.name, flag, err)
+		}
+		if !tt.up && (err == nil || flag != FlagDown) {
+			t.Errorf("%s: expected down, got %d", tt.name, flag)
+		}
+	}
+}
+
+func TestHTTPCheckConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings config.HTTPCheckConfig
+	}{
+		{"bad status", config.HTTPCheckConfig{Status: "2xx"}},
+		{"bad regexp", config.HTTPCheckConfig{BodyRegexp: "("}},
+		{"HEAD with body", config.HTTPCheckConfig{Method: "head", Body: "ok"}},
+		{"bad timeout", config.HTTPCheckConfig{Timeout: "soon"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewHealthHTTP(&tt.settings); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
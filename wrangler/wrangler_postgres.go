@@ -0,0 +1,131 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"fmt"
+	"github.com/zhgwenming/gbalancer/config"
+	"github.com/zhgwenming/gbalancer/pgproto"
+	"net"
+	"strconv"
+	"time"
+)
+
+// the replay lag is 0 while the standby has replayed all it received,
+// otherwise the age of the last replayed transaction
+const postgresStatusQuery = `SELECT pg_is_in_recovery(),
+	CASE WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END`
+
+// Postgres checks the servers of a streaming replication setup, the server
+// out of recovery is the primary, and the standbys are replicas as long as
+// they keep up with it
+type Postgres struct {
+	User     string
+	Pass     string
+	Database string
+	MaxLag   time.Duration
+	Director []string
+	errors   map[string]error
+}
+
+func NewPostgres(user, pass string, settings *config.ReplicaConfig) (*Postgres, error) {
+	maxLag := ReplicaMaxLag * time.Second
+	if settings.MaxLag != "" {
+		var err error
+		if maxLag, err = time.ParseDuration(settings.MaxLag); err != nil {
+			return nil, fmt.Errorf("Replica.MaxLag: %s", err)
+		}
+	}
+
+	database := settings.Database
+	if database == "" {
+		database = PostgresDatabase
+	}
+
+	dir := make([]string, 0, MaxBackends)
+	errors := make(map[string]error, MaxBackends)
+	return &Postgres{user, pass, database, maxLag, dir, errors}, nil
+}
+
+func (p *Postgres) AddDirector(backend string) error {
+	p.Director = append(p.Director, backend)
+	return fmt.Errorf("Error to add backend %s\n", backend)
+}
+
+func postgresProbe(login *pgproto.Login, host string, maxLag time.Duration) (int, error) {
+	c, err := net.DialTimeout("tcp", host, time.Second)
+	if err != nil {
+		return FlagDown, err
+	}
+	c.SetDeadline(time.Now().Add(PostgresTimeout * time.Second))
+
+	conn := pgproto.NewConn(c)
+	defer conn.Terminate()
+
+	if err := pgproto.Connect(conn, login); err != nil {
+		return FlagDown, fmt.Errorf("%s: %s", host, err)
+	}
+
+	rows, err := conn.Query(postgresStatusQuery)
+	if err != nil {
+		return FlagDown, fmt.Errorf("%s: %s", host, err)
+	}
+	if len(rows) != 1 {
+		return FlagDown, fmt.Errorf("%s: unexpected status %q", host, rows)
+	}
+
+	flag, err := postgresRole(rows[0], maxLag)
+	if err != nil {
+		return FlagDown, fmt.Errorf("%s: %s", host, err)
+	}
+	return flag, nil
+}
+
+// postgresRole classifies a server from the row of the status query
+func postgresRole(row []string, maxLag time.Duration) (int, error) {
+	if len(row) != 2 {
+		return FlagDown, fmt.Errorf("unexpected status %q", row)
+	}
+
+	switch row[0] {
+	case "f":
+		return FlagPrimary, nil
+	case "t":
+	default:
+		return FlagDown, fmt.Errorf("unexpected recovery state %q", row[0])
+	}
+
+	lag, err := strconv.ParseFloat(row[1], 64)
+	if err != nil {
+		return FlagDown, fmt.Errorf("replay lag %q: %s", row[1], err)
+	}
+	if time.Duration(lag*float64(time.Second)) > maxLag {
+		return FlagDown, fmt.Errorf("%.0fs behind the primary", lag)
+	}
+	return FlagReplica, nil
+}
+
+// Probe checks a single server
+func (p *Postgres) Probe(addr string) (int, error) {
+	login := &pgproto.Login{User: p.User, Password: p.Pass, Database: p.Database}
+	return postgresProbe(login, addr, p.MaxLag)
+}
+
+// the probe errors of the last check
+func (p *Postgres) ProbeErrors() map[string]error {
+	return p.errors
+}
+
+// check the backend status, the flags are the roles of the backends
+func (p *Postgres) BuildActiveBackends() (map[string]int, error) {
+	if len(p.Director) == 0 {
+		return make(map[string]int), fmt.Errorf("Empty directory server list\n")
+	}
+
+	backends, errors := probeRoles(p.Director, p.Probe)
+	p.errors = errors
+	return backends, nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPostgresRole(t *testing.T) {
+	tests := []struct {
+		row  []string
+		flag int
+		fail bool
+	}{
+		// the lag of a primary isn't looked at
+		{[]string{"f", "0"}, FlagPrimary, false},
+		{[]string{"f", ""}, FlagPrimary, false},
+		{[]string{"t", "0"}, FlagReplica, false},
+		{[]string{"t", "12.5"}, FlagReplica, false},
+		{[]string{"t", "30"}, FlagReplica, false},
+		{[]string{"t", "30.2"}, FlagDown, true},
+		{[]string{"t", "3600"}, FlagDown, true},
+		{[]string{"t", ""}, FlagDown, true},
+		{[]string{"t", "NaN days"}, FlagDown, true},
+		{[]string{"", "0"}, FlagDown, true},
+		{[]string{"t"}, FlagDown, true},
+		{[]string{"t", "0", "0"}, FlagDown, true},
+	}
+
+	for _, tt := range tests {
+		flag, err := postgresRole(tt.row, 30*time.Second)
+		if flag != tt.flag || (err != nil) != tt.fail {
+			t.Errorf("%q: got %d %v, expected %d", tt.row, flag, err, tt.flag)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"github.com/zhgwenming/gbalancer/config"
+	"math/rand"
+	"time"
+)
+
+// backendProber checks a single backend, the drivers finding the backends
+// from the cluster like galera check all of them in rounds instead
+type backendProber interface {
+	Probe(addr string) (int, error)
+}
+
+// proberOf returns the driver if it checks the backends one by one
+func proberOf(h healthDriver) backendProber {
+	if m, ok := h.(*HealthMapped); ok && !m.probing() {
+		return nil
+	}
+	prober, _ := h.(backendProber)
+	return prober
+}
+
+type probeResult struct {
+	backend string
+	flag    int
+	err     error
+}
+
+// jitter spreads the checks over ±10% of the interval
+func jitter(d time.Duration) time.Duration {
+	if d < 10 {
+		return d
+	}
+	return d - d/10 + time.Duration(rand.Int63n(int64(d/5)))
+}
+
+// monitorBackends checks every backend in a goroutine of its own, and the
+// engines get the state of a backend as soon as it changed. It returns the
+// new settings once the config is updated.
+func (w *Wrangler) monitorBackends(prober backendProber) *config.Configuration {
+	results := make(chan probeResult, MaxBackends)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// the backends no longer configured are gone
+	configured := make(map[string]bool, len(w.config.Backend))
+	for _, addr := range w.config.Backend {
+		configured[addr] = true
+	}
+
+	changed := false
+	w.mutex.Lock()
+	for b := range w.status {
+		if !configured[b] {
+			delete(w.status, b)
+		}
+	}
+	w.mutex.Unlock()
+	for b := range w.Backends {
+		if !configured[b] {
+			delete(w.Backends, b)
+			log.Warnf("wrangler: server %s is removed\n", b)
+			changed = true
+		}
+	}
+	if changed {
+		w.sendBackends()
+	}
+
+	for addr := range configured {
+		go probeLoop(prober, addr, w.interval, w.failInterval, results, stop)
+	}
+
+	for {
+		select {
+		case r := <-results:
+			w.applyResult(r)
+			if r.err == nil {
+				w.sendBackends(r.backend)
+			} else {
+				w.sendBackends()
+			}
+		case settings := <-w.updates:
+			return settings
+		}
+	}
+}
+
+// probeLoop checks a backend right away and then on its own schedule
+func probeLoop(prober backendProber, addr string, interval, failInterval time.Duration,
+	results chan<- probeResult, stop <-chan struct{}) {
+	for {
+		flag, err := prober.Probe(addr)
+		select {
+		case results <- probeResult{addr, flag, err}:
+		case <-stop:
+			return
+		}
+
+		next := interval
+		if err != nil {
+			next = failInterval
+		}
+		select {
+		case <-time.After(jitter(next)):
+		case <-stop:
+			return
+		}
+	}
+}
+
+// applyResult records the result of a check
+func (w *Wrangler) applyResult(r probeResult) {
+	w.mutex.Lock()
+	w.status[r.backend] = r.err
+	total := len(w.status)
+	w.mutex.Unlock()
+
+	old, up := w.Backends[r.backend]
+	switch {
+	case r.err != nil && up:
+		delete(w.Backends, r.backend)
+		log.Warnf("wrangler: detected server %s is down: %s\n", r.backend, r.err)
+	case r.err != nil:
+		log.Debugf("wrangler: server %s is still down: %s\n", r.backend, r.err)
+	case !up:
+		log.Printf("wrangler: detected server %s is %s\n", r.backend, FlagString(r.flag))
+		w.Backends[r.backend] = r.flag
+	case old != r.flag:
+		log.Printf("wrangler: detected server %s is now %s\n", r.backend, FlagString(r.flag))
+		w.Backends[r.backend] = r.flag
+	}
+
+	w.notifyStatus(len(w.Backends), total)
+}
+
+// sendBackends gives the engines the backends changed since the last time,
+// FlagDown takes one out. The passed ones are sent again as the engines
+// take a backend out once it failed a connection. The engines keep the
+// last ones if none is active.
+func (w *Wrangler) sendBackends(passed ...string) {
+	if len(w.Backends) == 0 {
+		return
+	}
+
+	changes := make(map[string]int)
+	for _, b := range passed {
+		if flag, ok := w.Backends[b]; ok {
+			changes[b] = flag
+		}
+	}
+	for b, flag := range w.Backends {
+		if old, ok := w.sent[b]; !ok || old != flag {
+			changes[b] = flag
+		}
+	}
+	for b := range w.sent {
+		if _, ok := w.Backends[b]; !ok {
+			changes[b] = FlagDown
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	for b, flag := range changes {
+		if flag == FlagDown {
+			delete(w.sent, b)
+		} else {
+			w.sent[b] = flag
+		}
+	}
+	w.BackChan <- changes
+	w.notifyReady()
+}
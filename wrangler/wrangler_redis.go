@@ -0,0 +1,173 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/zhgwenming/gbalancer/config"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Redis checks the nodes of a replicated redis, the master is the primary
+// and the replicas are up while their link to the master is, a link down
+// for less than MaxLag is tolerated
+type Redis struct {
+	User     string
+	Pass     string
+	MaxLag   time.Duration
+	Director []string
+	errors   map[string]error
+}
+
+func NewRedis(user, pass string, settings *config.ReplicaConfig) (*Redis, error) {
+	maxLag := ReplicaMaxLag * time.Second
+	if settings.MaxLag != "" {
+		var err error
+		if maxLag, err = time.ParseDuration(settings.MaxLag); err != nil {
+			return nil, fmt.Errorf("Replica.MaxLag: %s", err)
+		}
+	}
+
+	dir := make([]string, 0, MaxBackends)
+	errors := make(map[string]error, MaxBackends)
+	return &Redis{user, pass, maxLag, dir, errors}, nil
+}
+
+func (r *Redis) AddDirector(backend string) error {
+	r.Director = append(r.Director, backend)
+	return fmt.Errorf("Error to add backend %s\n", backend)
+}
+
+// redisCommand sends a command and returns the simple or bulk string reply
+func redisCommand(conn net.Conn, reader *bufio.Reader, args ...string) (string, error) {
+	cmd := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		cmd += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(conn, cmd); err != nil {
+		return "", err
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("empty reply to %s", args[0])
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", fmt.Errorf("%s: %s", args[0], line[1:])
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil || length < 0 {
+			return "", fmt.Errorf("%s: unexpected reply %q", args[0], line)
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return "", err
+		}
+		return string(data[:length]), nil
+	}
+	return "", fmt.Errorf("%s: unexpected reply %q", args[0], line)
+}
+
+func redisProbe(user, pass, host string, maxLag time.Duration) (int, error) {
+	conn, err := net.DialTimeout("tcp", host, time.Second)
+	if err != nil {
+		return FlagDown, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(RedisTimeout * time.Second))
+	reader := bufio.NewReader(conn)
+
+	if pass != "" {
+		args := []string{"AUTH", pass}
+		if user != "" {
+			args = []string{"AUTH", user, pass}
+		}
+		if _, err := redisCommand(conn, reader, args...); err != nil {
+			return FlagDown, fmt.Errorf("%s: %s", host, err)
+		}
+	}
+
+	// a node loading the dataset answers with a LOADING error
+	if _, err := redisCommand(conn, reader, "PING"); err != nil {
+		return FlagDown, fmt.Errorf("%s: %s", host, err)
+	}
+
+	info, err := redisCommand(conn, reader, "INFO", "replication")
+	if err != nil {
+		return FlagDown, fmt.Errorf("%s: %s", host, err)
+	}
+
+	flag, err := redisRole(info, maxLag)
+	if err != nil {
+		return FlagDown, fmt.Errorf("%s: %s", host, err)
+	}
+	return flag, nil
+}
+
+// redisRole classifies a node from its INFO replication section
+func redisRole(info string, maxLag time.Duration) (int, error) {
+	status := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		if kv := strings.SplitN(strings.TrimSpace(line), ":", 2); len(kv) == 2 {
+			status[kv[0]] = kv[1]
+		}
+	}
+
+	switch status["role"] {
+	case "master":
+		return FlagPrimary, nil
+	case "slave":
+	default:
+		return FlagDown, fmt.Errorf("unknown role %q", status["role"])
+	}
+
+	if status["master_link_status"] == "up" {
+		return FlagReplica, nil
+	}
+
+	// -1 if the link never got up
+	since, err := strconv.Atoi(status["master_link_down_since_seconds"])
+	if err != nil || since < 0 {
+		return FlagDown, fmt.Errorf("link to the master is down")
+	}
+	if time.Duration(since)*time.Second > maxLag {
+		return FlagDown, fmt.Errorf("link to the master down for %ds", since)
+	}
+	return FlagReplica, nil
+}
+
+// Probe checks a single node
+func (r *Redis) Probe(addr string) (int, error) {
+	return redisProbe(r.User, r.Pass, addr, r.MaxLag)
+}
+
+// the probe errors of the last check
+func (r *Redis) ProbeErrors() map[string]error {
+	return r.errors
+}
+
+// check the backend status, the flags are the roles of the backends
+func (r *Redis) BuildActiveBackends() (map[string]int, error) {
+	if len(r.Director) == 0 {
+		return make(map[string]int), fmt.Errorf("Empty directory server list\n")
+	}
+
+	backends, errors := probeRoles(r.Director, r.Probe)
+	r.errors = errors
+	return backends, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// replication returns an INFO replication reply
+func replication(lines ...string) string {
+	return "# Replication\r\n" + strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func TestRedisRole(t *testing.T) {
+	tests := []struct {
+		info string
+		flag int
+		fail bool
+	}{
+		{replication("role:master", "connected_slaves:1", "slave0:ip=10.0.0.2,port=6379,state=online,offset=42,lag=0"), FlagPrimary, false},
+		{replication("role:slave", "master_host:10.0.0.1", "master_port:6379", "master_link_status:up"), FlagReplica, false},
+		{replication("role:slave", "master_link_status:down", "master_link_down_since_seconds:10"), FlagReplica, false},
+		{replication("role:slave", "master_link_status:down", "master_link_down_since_seconds:30"), FlagReplica, false},
+		{replication("role:slave", "master_link_status:down", "master_link_down_since_seconds:31"), FlagDown, true},
+		// the link never got up
+		{replication("role:slave", "master_link_status:down", "master_link_down_since_seconds:-1"), FlagDown, true},
+		{replication("role:slave", "master_link_status:down"), FlagDown, true},
+		{replication("role:slave"), FlagDown, true},
+		{replication("role:sentinel"), FlagDown, true},
+		{"", FlagDown, true},
+		// newline only replies
+		{"role:slave\nmaster_link_status:up\n", FlagReplica, false},
+	}
+
+	for _, tt := range tests {
+		flag, err := redisRole(tt.info, 30*time.Second)
+		if flag != tt.flag || (err != nil) != tt.fail {
+			t.Errorf("%q: got %d %v, expected %d", tt.info, flag, err, tt.flag)
+		}
+	}
+}
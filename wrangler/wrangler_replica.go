@@ -0,0 +1,201 @@
+// Copyright 2014. All rights reserved.
+// Use of this source code is governed by a GPLv3
+// Author: Wenming Zhang <zhgwenming@gmail.com>
+
+package wrangler
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/zhgwenming/gbalancer/config"
+	"strconv"
+	"time"
+)
+
+// MySQLReplica checks the servers of a classic primary/replica setup, the
+// writable server is the primary, and the read only ones are replicas as
+// long as the replication runs and keeps up with the primary
+type MySQLReplica struct {
+	User     string
+	Pass     string
+	MaxLag   time.Duration
+	Director []string
+	errors   map[string]error
+}
+
+func NewMySQLReplica(user, pass string, settings *config.ReplicaConfig) (*MySQLReplica, error) {
+	maxLag := ReplicaMaxLag * time.Second
+	if settings.MaxLag != "" {
+		var err error
+		if maxLag, err = time.ParseDuration(settings.MaxLag); err != nil {
+			return nil, fmt.Errorf("Replica.MaxLag: %s", err)
+		}
+	}
+
+	dir := make([]string, 0, MaxBackends)
+	errors := make(map[string]error, MaxBackends)
+	return &MySQLReplica{user, pass, maxLag, dir, errors}, nil
+}
+
+func (r *MySQLReplica) AddDirector(backend string) error {
+	r.Director = append(r.Director, backend)
+	return fmt.Errorf("Error to add backend %s\n", backend)
+}
+
+// replicaStatus returns the rows of SHOW REPLICA STATUS, one per channel
+func replicaStatus(db *sql.DB) ([]map[string]string, error) {
+	rows, err := db.Query("SHOW REPLICA STATUS")
+	if err != nil {
+		// before MySQL 8.0.22
+		if rows, err = db.Query("SHOW SLAVE STATUS"); err != nil {
+			return nil, err
+		}
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var status []map[string]string
+	for rows.Next() {
+		values := make([]sql.RawBytes, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		channel := make(map[string]string, len(columns))
+		for i, c := range columns {
+			if values[i] != nil {
+				channel[c] = string(values[i])
+			}
+		}
+		status = append(status, channel)
+	}
+	return status, rows.Err()
+}
+
+// field returns the column under the new or the old name, false for NULL
+func field(status map[string]string, names ...string) (string, bool) {
+	for _, name := range names {
+		if v, ok := status[name]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func replicaProbe(user, pass, host string, maxLag time.Duration) (int, error) {
+	dsn := user + ":" + pass + "@tcp(" + host + ")/?timeout=1s"
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return FlagDown, err
+	}
+	defer db.Close()
+
+	var readOnly int
+	if err := db.QueryRow("SELECT @@global.read_only").Scan(&readOnly); err != nil {
+		return FlagDown, err
+	}
+
+	// a writable server is the primary whether it replicates or not
+	if readOnly == 0 {
+		return FlagPrimary, nil
+	}
+
+	status, err := replicaStatus(db)
+	if err != nil {
+		return FlagDown, err
+	}
+	if len(status) == 0 {
+		return FlagDown, fmt.Errorf("%s: read only without replication", host)
+	}
+
+	for _, channel := range status {
+		if v, _ := field(channel, "Replica_IO_Running", "Slave_IO_Running"); v != "Yes" {
+			return FlagDown, fmt.Errorf("%s: replication IO thread not running", host)
+		}
+		if v, _ := field(channel, "Replica_SQL_Running", "Slave_SQL_Running"); v != "Yes" {
+			return FlagDown, fmt.Errorf("%s: replication SQL thread not running", host)
+		}
+
+		v, ok := field(channel, "Seconds_Behind_Source", "Seconds_Behind_Master")
+		if !ok {
+			return FlagDown, fmt.Errorf("%s: unknown replication lag", host)
+		}
+		lag, err := strconv.Atoi(v)
+		if err != nil {
+			return FlagDown, fmt.Errorf("%s: replication lag %q: %s", host, v, err)
+		}
+		if time.Duration(lag)*time.Second > maxLag {
+			return FlagDown, fmt.Errorf("%s: %ds behind the primary", host, lag)
+		}
+	}
+	return FlagReplica, nil
+}
+
+// Probe checks a single server
+func (r *MySQLReplica) Probe(addr string) (int, error) {
+	return replicaProbe(r.User, r.Pass, addr, r.MaxLag)
+}
+
+// the probe errors of the last check
+func (r *MySQLReplica) ProbeErrors() map[string]error {
+	return r.errors
+}
+
+// check the backend status, the flags are the roles of the backends
+func (r *MySQLReplica) BuildActiveBackends() (map[string]int, error) {
+	if len(r.Director) == 0 {
+		return make(map[string]int), fmt.Errorf("Empty directory server list\n")
+	}
+
+	backends, errors := probeRoles(r.Director, r.Probe)
+	r.errors = errors
+	return backends, nil
+}
+
+// probeRoles checks the servers concurrently, the flags are their roles
+func probeRoles(director []string, probe func(addr string) (int, error)) (map[string]int, map[string]error) {
+	type backendStatus struct {
+		backend string
+		role    int
+		err     error
+	}
+
+	results := make(chan backendStatus, MaxBackends)
+	backends := make(map[string]int, MaxBackends)
+	errors := make(map[string]error, MaxBackends)
+
+	for _, addr := range director {
+		go func(addr string) {
+			role, err := probe(addr)
+			results <- backendStatus{addr, role, err}
+		}(addr)
+	}
+
+	var primaries []string
+	for i := 0; i < len(director); i++ {
+		s := <-results
+		if s.err != nil {
+			errors[s.backend] = s.err
+			log.Warnf("node not ready: %s", s.err)
+			continue
+		}
+
+		backends[s.backend] = s.role
+		if s.role == FlagPrimary {
+			primaries = append(primaries, s.backend)
+		}
+	}
+
+	if len(primaries) > 1 {
+		log.Warnf("wrangler: more than one writable server %v", primaries)
+	}
+	return backends, errors
+}
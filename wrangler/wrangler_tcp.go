@@ -13,11 +13,13 @@ import (
 
 type HealthTcp struct {
 	Director []string
+	errors   map[string]error
 }
 
 func NewHealthTcp() *HealthTcp {
 	dir := make([]string, 0, MaxBackends)
-	return &HealthTcp{dir}
+	errors := make(map[string]error, MaxBackends)
+	return &HealthTcp{dir, errors}
 }
 
 func (c *HealthTcp) AddDirector(backend string) error {
@@ -35,6 +37,19 @@ func tcpProbe(addr string) error {
 	return err
 }
 
+// Probe checks a single backend
+func (t *HealthTcp) Probe(addr string) (int, error) {
+	if err := tcpProbe(addr); err != nil {
+		return FlagDown, err
+	}
+	return FlagUp, nil
+}
+
+// the probe errors of the last check
+func (t *HealthTcp) ProbeErrors() map[string]error {
+	return t.errors
+}
+
 // check the backend status
 func (t *HealthTcp) BuildActiveBackends() (map[string]int, error) {
 	backends := make(map[string]int, MaxBackends)
@@ -49,6 +64,7 @@ func (t *HealthTcp) BuildActiveBackends() (map[string]int, error) {
 	}
 
 	results := make(chan backendStatus, MaxBackends)
+	t.errors = make(map[string]error, MaxBackends)
 
 	probe := func(addr string) {
 		err := tcpProbe(addr)
@@ -66,7 +82,8 @@ func (t *HealthTcp) BuildActiveBackends() (map[string]int, error) {
 			backends[r.backend] = FlagUp
 			//log.Printf("host: %s\n", r.backend)
 		} else {
-			log.Printf("error: %s", r.err)
+			t.errors[r.backend] = r.err
+			log.Warnf("error: %s", r.err)
 		}
 	}
 	//log.Printf("Active server: %v\n", backends)
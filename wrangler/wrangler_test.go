@@ -5,20 +5,91 @@
 package wrangler
 
 import (
-	//"log"
+	"errors"
+	"github.com/zhgwenming/gbalancer/config"
+	"reflect"
 	"testing"
 )
 
-//mysql -u monitor -p3FNg6IKe
+var errDown = errors.New("down")
 
 func TestCheck(t *testing.T) {
-	//log.Printf("hello\n")
-	back := make(chan *BEStatus, MaxBackends)
-	g := NewGaleraChecker("monitor", "3FNg6IKe", back)
-	server1 := "11.100.91.72:3306"
-	server2 := "10.100.91.71:3306"
-	g.AddDirector(&server1)
-	g.AddDirector(&server2)
-	//g.BuildActiveBackends()
-	g.Monitor()
+	g, err := NewGalera("monitor", "", &config.GaleraConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.BuildActiveBackends(); err == nil {
+		t.Error("expected an error without directors")
+	}
+
+	// nothing listens on the port
+	server := "127.0.0.1:1"
+	g.AddDirector(server)
+	backends, err := g.BuildActiveBackends()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backends) != 0 {
+		t.Errorf("unexpected backends %v", backends)
+	}
+	if g.ProbeErrors()[server] == nil {
+		t.Errorf("expected the probe error of %s", server)
+	}
+}
+
+func TestSendBackends(t *testing.T) {
+	back := make(chan map[string]int, MaxBackends)
+	w, err := NewWrangler(&config.Configuration{Service: "tcp"}, back)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		result  probeResult
+		changes map[string]int
+	}{
+		{probeResult{"a:1", FlagUp, nil}, map[string]int{"a:1": FlagUp}},
+		{probeResult{"b:1", FlagPrimary, nil}, map[string]int{"b:1": FlagPrimary}},
+		// a passed check is sent again to bring it back to the engines
+		{probeResult{"a:1", FlagUp, nil}, map[string]int{"a:1": FlagUp}},
+		{probeResult{"b:1", FlagReplica, nil}, map[string]int{"b:1": FlagReplica}},
+		{probeResult{"a:1", FlagDown, errDown}, map[string]int{"a:1": FlagDown}},
+		// the last one is kept
+		{probeResult{"b:1", FlagDown, errDown}, nil},
+		{probeResult{"b:1", FlagDown, errDown}, nil},
+		{probeResult{"a:1", FlagUp, nil}, map[string]int{"a:1": FlagUp, "b:1": FlagDown}},
+	}
+
+	for i, tt := range tests {
+		w.applyResult(tt.result)
+		if tt.result.err == nil {
+			w.sendBackends(tt.result.backend)
+		} else {
+			w.sendBackends()
+		}
+
+		var changes map[string]int
+		select {
+		case changes = <-back:
+		default:
+		}
+		if !reflect.DeepEqual(changes, tt.changes) {
+			t.Errorf("%d: got %v, expected %v", i, changes, tt.changes)
+		}
+	}
+}
+
+func TestNewWranglerErrors(t *testing.T) {
+	tests := []config.Configuration{
+		{Service: "unknown"},
+		{Service: "tcp", Health: config.HealthConfig{Interval: "soon"}},
+		{Service: "tcp", Health: config.HealthConfig{FailInterval: "-1s"}},
+	}
+
+	for i, tt := range tests {
+		if _, err := NewWrangler(&tt, nil); err == nil {
+			t.Errorf("%d: expected an error", i)
+		}
+	}
 }